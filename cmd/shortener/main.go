@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/cmrd-a/shortener/internal/config"
+	"github.com/cmrd-a/shortener/internal/denylist"
 	"github.com/cmrd-a/shortener/internal/logger"
 	"github.com/cmrd-a/shortener/internal/server"
 	"github.com/cmrd-a/shortener/internal/service"
@@ -55,9 +56,57 @@ func main() {
 	if err != nil {
 		log.Fatalf("ERROR: failed to initialize repository %s \n", err)
 	}
-	generator := service.NewShortGenerator()
-	svc := service.NewURLService(generator, cfg.BaseURL, repo)
-	s := server.NewServer(zl, svc)
+	generator, err := service.MakeGenerator(cfg)
+	if err != nil {
+		log.Fatalf("ERROR: failed to initialize id generator %s \n", err)
+	}
+	if err := service.ValidateRedirectType(cfg.DefaultRedirectType); err != nil {
+		log.Fatalf("ERROR: bad default redirect type %s \n", err)
+	}
+	if cfg.DeletionBufferSize < 1 || cfg.DeletionBatchSize < 1 || cfg.DeletionFlush <= 0 {
+		log.Fatalf("ERROR: deletion buffer size, batch size and flush interval must be positive \n")
+	}
+	if cfg.ExpirySweepInterval <= 0 || cfg.PurgeInterval <= 0 {
+		log.Fatalf("ERROR: expiry sweep and purge intervals must be positive \n")
+	}
+	svcCtx, stopService := context.WithCancel(ctx)
+	defer stopService()
+	svcOpts := []service.Option{
+		service.WithContext(svcCtx),
+		service.WithMaxRetries(cfg.IDMaxRetries),
+		service.WithExpirySweepInterval(cfg.ExpirySweepInterval),
+		service.WithAnonymizeIP(cfg.AnonymizeIP),
+		service.WithDefaultRedirectType(cfg.DefaultRedirectType),
+		service.WithTrashRetention(cfg.TrashRetention),
+		service.WithPurgeRetention(cfg.PurgeRetention),
+		service.WithPurgeInterval(cfg.PurgeInterval),
+		service.WithDeletionBuffer(cfg.DeletionBufferSize),
+		service.WithDeletionFlushInterval(cfg.DeletionFlush),
+		service.WithDeletionBatchSize(cfg.DeletionBatchSize),
+		service.WithURLNormalizer(service.NewURLNormalizer(cfg.URLAllowedSchemes, cfg.URLMaxLength, cfg.URLStripFragment)),
+		service.WithLogger(zl),
+	}
+	if cfg.DenylistPath != "" {
+		dl, err := denylist.New(svcCtx, cfg.DenylistPath, cfg.DenylistReload, zl)
+		if err != nil {
+			log.Fatalf("ERROR: failed to load denylist %s \n", err)
+		}
+		svcOpts = append(svcOpts, service.WithBlocker(dl))
+	}
+	if cfg.DurableDeletes {
+		if q, ok := repo.(storage.DeletionQueue); ok {
+			svcOpts = append(svcOpts, service.WithDeletionQueue(q))
+		} else {
+			zl.Warn("durable deletes are not supported by in-memory storage")
+		}
+	}
+	svc := service.NewURLService(generator, cfg.BaseURL, repo, svcOpts...)
+	if cfg.TemplatesDir != "" {
+		if err := server.LoadTemplates(cfg.TemplatesDir); err != nil {
+			log.Fatalf("ERROR: %s \n", err)
+		}
+	}
+	s := server.NewServer(zl, svc, server.WithAdminToken(cfg.AdminToken))
 	defer func(Log *zap.Logger) {
 		err := Log.Sync()
 		if err != nil {
@@ -108,6 +157,15 @@ func main() {
 		zl.Info("Server shutdown completed")
 	}
 
+	// Stop background jobs, letting them flush queued deletions and clicks
+	zl.Info("Flushing background jobs...")
+	stopService()
+	if err := svc.Wait(ctx); err != nil {
+		zl.Error("Background jobs did not finish in time", zap.Error(err))
+	} else {
+		zl.Info("Background jobs finished")
+	}
+
 	// Close storage repository if it has a Close method
 	if closer, ok := repo.(interface{ Close() error }); ok {
 		zl.Info("Closing storage repository...")
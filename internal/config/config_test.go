@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestNewConfigJSONLoading(t *testing.T) {
@@ -13,7 +14,9 @@ func TestNewConfigJSONLoading(t *testing.T) {
 		"log_level": "debug",
 		"file_storage_path": "/tmp/test.db",
 		"database_dsn": "postgres://test",
-		"enable_https": true
+		"enable_https": true,
+		"expiry_sweep_interval": "90s",
+		"dedup_scope": "user"
 	}`
 
 	tmpFile, err := os.CreateTemp("", "config_test_*.json")
@@ -59,6 +62,12 @@ func TestNewConfigJSONLoading(t *testing.T) {
 	if !cfg.EnableHTTPS {
 		t.Errorf("Expected EnableHTTPS to be true, got false")
 	}
+	if cfg.ExpirySweepInterval != 90*time.Second {
+		t.Errorf("Expected ExpirySweepInterval to be 90s, got '%s'", cfg.ExpirySweepInterval)
+	}
+	if cfg.DedupScope != "user" {
+		t.Errorf("Expected DedupScope to be 'user', got '%s'", cfg.DedupScope)
+	}
 }
 
 func TestConfigPriorityOrder(t *testing.T) {
@@ -0,0 +1,195 @@
+// Package denylist blocks URLs by their host.
+//
+// A denylist file holds one rule per line; blank lines and lines starting with '#' are ignored.
+// A rule is one of:
+//
+//	example.com       the domain itself
+//	*.example.com     any subdomain of example.com, but not example.com itself
+//	/^ads?\d+\./      a regular expression matched against the host
+//
+// Domains are compared case-insensitively in their ASCII (punycode) form.
+package denylist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/idna"
+)
+
+// rule is a single parsed line of a denylist file.
+type rule struct {
+	raw    string
+	domain string
+	suffix string
+	re     *regexp.Regexp
+}
+
+func (r rule) match(host string) bool {
+	switch {
+	case r.re != nil:
+		return r.re.MatchString(host)
+	case r.suffix != "":
+		return strings.HasSuffix(host, r.suffix)
+	}
+	return host == r.domain
+}
+
+// Rules is an immutable set of denylist rules.
+type Rules struct {
+	rules []rule
+}
+
+// Parse reads denylist rules from data.
+// It returns an error naming the line of the first invalid rule.
+func Parse(data []byte) (*Rules, error) {
+	rs := &Rules{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rs.rules = append(rs.rules, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func parseRule(line string) (rule, error) {
+	if len(line) > 1 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/") {
+		re, err := regexp.Compile(line[1 : len(line)-1])
+		if err != nil {
+			return rule{}, err
+		}
+		return rule{raw: line, re: re}, nil
+	}
+	if domain, ok := strings.CutPrefix(line, "*."); ok {
+		ascii, err := toASCII(domain)
+		if err != nil {
+			return rule{}, err
+		}
+		return rule{raw: line, suffix: "." + ascii}, nil
+	}
+	ascii, err := toASCII(line)
+	if err != nil {
+		return rule{}, err
+	}
+	return rule{raw: line, domain: ascii}, nil
+}
+
+// Match reports whether the host is denied and returns the rule that matched.
+func (rs *Rules) Match(host string) (string, bool) {
+	ascii, err := toASCII(host)
+	if err != nil {
+		ascii = strings.ToLower(host)
+	}
+	for _, r := range rs.rules {
+		if r.match(ascii) {
+			return r.raw, true
+		}
+	}
+	return "", false
+}
+
+// Len returns the number of rules.
+func (rs *Rules) Len() int {
+	return len(rs.rules)
+}
+
+func toASCII(domain string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return "", fmt.Errorf("bad domain %q: %w", domain, err)
+	}
+	return ascii, nil
+}
+
+// Denylist holds the rules loaded from a file and reloads them when the file changes.
+type Denylist struct {
+	path    string
+	rules   atomic.Pointer[Rules]
+	modTime time.Time
+	size    int64
+	log     *zap.Logger
+	done    chan struct{}
+}
+
+// New loads the denylist file and starts polling it for changes every interval until ctx is cancelled.
+// A file that fails to load on reload is logged and the previous rules stay in effect.
+func New(ctx context.Context, path string, interval time.Duration, log *zap.Logger) (*Denylist, error) {
+	d := &Denylist{path: path, log: log, done: make(chan struct{})}
+	if _, err := d.reload(); err != nil {
+		return nil, err
+	}
+	go d.watch(ctx, interval)
+	return d, nil
+}
+
+// Blocked reports whether the host of rawURL is denied and returns the rule that matched.
+func (d *Denylist) Blocked(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+	return d.rules.Load().Match(u.Hostname())
+}
+
+// reload reads the file if it changed since the last load and reports whether the rules were replaced.
+func (d *Denylist) reload() (bool, error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return false, err
+	}
+	if d.rules.Load() != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+		return false, nil
+	}
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return false, err
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return false, fmt.Errorf("denylist %s: %w", d.path, err)
+	}
+	d.rules.Store(rules)
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+	return true, nil
+}
+
+func (d *Denylist) watch(ctx context.Context, interval time.Duration) {
+	defer close(d.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := d.reload()
+		if err != nil {
+			d.log.Error("failed to reload denylist", zap.String("path", d.path), zap.Error(err))
+			continue
+		}
+		if reloaded {
+			d.log.Info("reloaded denylist", zap.String("path", d.path), zap.Int("rules", d.rules.Load().Len()))
+		}
+	}
+}
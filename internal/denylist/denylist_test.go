@@ -0,0 +1,109 @@
+package denylist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testRules = `
+# exact domains
+evil.example
+Пример.рф
+
+# subdomains
+*.tracker.example
+
+# regular expressions
+/^ads?[0-9]+\./
+`
+
+func TestRulesMatch(t *testing.T) {
+	rules, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+	require.Equal(t, 4, rules.Len())
+
+	tests := []struct {
+		host string
+		rule string
+	}{
+		{host: "evil.example", rule: "evil.example"},
+		{host: "EVIL.example.", rule: "evil.example"},
+		{host: "www.evil.example", rule: ""},
+		{host: "xn--e1afmkfd.xn--p1ai", rule: "Пример.рф"},
+		{host: "пример.рф", rule: "Пример.рф"},
+		{host: "a.b.tracker.example", rule: "*.tracker.example"},
+		{host: "tracker.example", rule: ""},
+		{host: "ad12.cdn.example", rule: `/^ads?[0-9]+\./`},
+		{host: "good.example", rule: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rule, blocked := rules.Match(tt.host)
+			require.Equal(t, tt.rule != "", blocked)
+			require.Equal(t, tt.rule, rule)
+		})
+	}
+}
+
+func TestParseInvalidRule(t *testing.T) {
+	_, err := Parse([]byte("good.example\n/[unclosed/\n"))
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestDenylistReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o600))
+
+	d, err := New(t.Context(), path, 10*time.Millisecond, zap.NewNop())
+	require.NoError(t, err)
+	_, blocked := d.Blocked("https://evil.example/path")
+	require.True(t, blocked)
+	_, blocked = d.Blocked("https://bad.example/path")
+	require.False(t, blocked)
+
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\nbad.example\n"), 0o600))
+	require.Eventually(t, func() bool {
+		_, blocked := d.Blocked("https://bad.example/path")
+		return blocked
+	}, time.Second, 10*time.Millisecond)
+
+	// a broken file keeps the previous rules in effect
+	require.NoError(t, os.WriteFile(path, []byte("/[broken/\n"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	_, blocked = d.Blocked("https://bad.example/path")
+	require.True(t, blocked)
+}
+
+func TestNewMissingFile(t *testing.T) {
+	_, err := New(t.Context(), filepath.Join(t.TempDir(), "missing.txt"), time.Second, zap.NewNop())
+	require.Error(t, err)
+}
+
+func TestDenylistStopsWatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	d, err := New(ctx, path, 10*time.Millisecond, zap.NewNop())
+	require.NoError(t, err)
+	cancel()
+	select {
+	case <-d.done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop after the context was cancelled")
+	}
+
+	// rules loaded before the stop stay in effect, later changes are not picked up
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\nbad.example\n"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	_, blocked := d.Blocked("https://evil.example/path")
+	require.True(t, blocked)
+	_, blocked = d.Blocked("https://bad.example/path")
+	require.False(t, blocked)
+}
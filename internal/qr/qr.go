@@ -0,0 +1,229 @@
+// Package qr renders QR codes as PNG or SVG images.
+//
+// Codes are encoded with a pure-Go encoder and drawn here, so that the size, the quiet zone
+// and the colours can be chosen freely. Nothing is fetched over the network.
+package qr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Limits and defaults of the image options.
+const (
+	DefaultSize   = 256
+	MinSize       = 32
+	MaxSize       = 2048
+	DefaultMargin = 4
+	MaxMargin     = 32
+)
+
+// ErrInvalidOptions is returned for options that can not be rendered.
+var ErrInvalidOptions = errors.New("invalid qr options")
+
+// Format is an image format of a rendered code.
+type Format string
+
+// Supported image formats.
+const (
+	PNG Format = "png"
+	SVG Format = "svg"
+)
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	if f == SVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Level is an error correction level: L, M, Q or H.
+type Level string
+
+// Supported error correction levels, recovering about 7%, 15%, 25% and 30% of the code.
+const (
+	LevelL Level = "L"
+	LevelM Level = "M"
+	LevelQ Level = "Q"
+	LevelH Level = "H"
+)
+
+var recoveryLevels = map[Level]qrcode.RecoveryLevel{
+	LevelL: qrcode.Low,
+	LevelM: qrcode.Medium,
+	LevelQ: qrcode.High,
+	LevelH: qrcode.Highest,
+}
+
+// ParseLevel parses an error correction level, case-insensitively.
+func ParseLevel(s string) (Level, error) {
+	level := Level(strings.ToUpper(s))
+	if _, ok := recoveryLevels[level]; !ok {
+		return "", fmt.Errorf("%w: unknown error correction level %q", ErrInvalidOptions, s)
+	}
+	return level, nil
+}
+
+// ParseColor parses a hex colour as RGB, RGBA, RRGGBB or RRGGBBAA, with an optional leading '#'.
+func ParseColor(s string) (color.NRGBA, error) {
+	hexDigits := strings.TrimPrefix(s, "#")
+	switch len(hexDigits) {
+	case 3, 4:
+		var b strings.Builder
+		for _, r := range hexDigits {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		hexDigits = b.String()
+	case 6, 8:
+	default:
+		return color.NRGBA{}, fmt.Errorf("%w: bad colour %q", ErrInvalidOptions, s)
+	}
+	if len(hexDigits) == 6 {
+		hexDigits += "ff"
+	}
+	v, err := strconv.ParseUint(hexDigits, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("%w: bad colour %q", ErrInvalidOptions, s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Options describe how a code is rendered.
+type Options struct {
+	Format Format
+	// Size is the width and height of the image in pixels.
+	Size int
+	// Margin is the width of the quiet zone around the code in modules.
+	Margin     int
+	Level      Level
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// DefaultOptions returns a black on white PNG of DefaultSize with the standard quiet zone.
+func DefaultOptions() Options {
+	return Options{
+		Format:     PNG,
+		Size:       DefaultSize,
+		Margin:     DefaultMargin,
+		Level:      LevelM,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// Validate checks that the options are within limits.
+func (o Options) Validate() error {
+	if o.Format != PNG && o.Format != SVG {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, o.Format)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("%w: size must be between %d and %d", ErrInvalidOptions, MinSize, MaxSize)
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("%w: margin must be between 0 and %d", ErrInvalidOptions, MaxMargin)
+	}
+	if _, ok := recoveryLevels[o.Level]; !ok {
+		return fmt.Errorf("%w: unknown error correction level %q", ErrInvalidOptions, o.Level)
+	}
+	return nil
+}
+
+// ETag returns a strong entity tag of the image of content rendered with the options.
+func (o Options) ETag(content string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s|%d|%d|%s|%s|%s",
+		content, o.Format, o.Size, o.Margin, o.Level, hexColor(o.Foreground), hexColor(o.Background)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Encode renders content as a QR code and writes the image to w.
+func Encode(w io.Writer, content string, o Options) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	code, err := qrcode.New(content, recoveryLevels[o.Level])
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidOptions, err)
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+	total := len(bitmap) + 2*o.Margin
+	if total > o.Size {
+		return fmt.Errorf("%w: size must be at least %d for this code", ErrInvalidOptions, total)
+	}
+	if o.Format == SVG {
+		return writeSVG(w, bitmap, total, o)
+	}
+	return writePNG(w, bitmap, total, o)
+}
+
+// writePNG draws whole pixels per module and centers the code, so that the image is exactly o.Size wide.
+func writePNG(w io.Writer, bitmap [][]bool, total int, o Options) error {
+	scale := o.Size / total
+	offset := (o.Size-scale*total)/2 + o.Margin*scale
+	img := image.NewPaletted(image.Rect(0, 0, o.Size, o.Size), color.Palette{o.Background, o.Foreground})
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			x0, y0 := offset+x*scale, offset+y*scale
+			for py := y0; py < y0+scale; py++ {
+				for px := x0; px < x0+scale; px++ {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// writeSVG draws the code in module units and lets the viewer scale it to o.Size.
+// Runs of dark modules in a row are merged into one path segment.
+func writeSVG(w io.Writer, bitmap [][]bool, total int, o Options) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		o.Size, o.Size, total, total)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d"%s/>`, total, total, svgFill(o.Background))
+	b.WriteString(`<path d="`)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start+o.Margin, y+o.Margin, x-start, x-start)
+		}
+	}
+	fmt.Fprintf(&b, `"%s/></svg>`, svgFill(o.Foreground))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// svgFill returns the fill attributes of a colour.
+func svgFill(c color.NRGBA) string {
+	attrs := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		attrs += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return attrs
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
@@ -0,0 +1,87 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodePNG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = 100
+	opts.Foreground = color.NRGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff}
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, "abc", opts))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 100, img.Bounds().Dx())
+	require.Equal(t, 100, img.Bounds().Dy())
+	// The corner lies in the quiet zone, the finder pattern starts right after it.
+	require.Equal(t, opts.Background, color.NRGBAModel.Convert(img.At(0, 0)))
+	scale := 100 / (21 + 2*DefaultMargin)
+	offset := (100-scale*(21+2*DefaultMargin))/2 + DefaultMargin*scale
+	require.Equal(t, opts.Foreground, color.NRGBAModel.Convert(img.At(offset, offset)))
+}
+
+func TestEncodeSVG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = SVG
+	opts.Margin = 0
+	opts.Background = color.NRGBA{}
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, "abc", opts))
+
+	svg := buf.String()
+	require.True(t, strings.HasPrefix(svg, "<svg "))
+	require.Contains(t, svg, `viewBox="0 0 21 21"`)
+	require.Contains(t, svg, `fill-opacity="0.000"`)
+	require.Contains(t, svg, "M0 0h7v1h-7z")
+}
+
+func TestEncodeTooSmall(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = MinSize
+	err := Encode(&bytes.Buffer{}, "http://localhost:8080/"+strings.Repeat("a", 100), opts)
+	require.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+		err  bool
+	}{
+		{in: "#000", want: color.NRGBA{A: 0xff}},
+		{in: "fff8", want: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x88}},
+		{in: "336699", want: color.NRGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff}},
+		{in: "#33669900", want: color.NRGBA{R: 0x33, G: 0x66, B: 0x99}},
+		{in: "blue", err: true},
+		{in: "#12345", err: true},
+		{in: "ggg", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInvalidOptions)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	opts := DefaultOptions()
+	etag := opts.ETag("http://localhost:8080/abc")
+	require.Equal(t, etag, opts.ETag("http://localhost:8080/abc"))
+	require.NotEqual(t, etag, opts.ETag("http://localhost:8080/abd"))
+	opts.Level = LevelH
+	require.NotEqual(t, etag, opts.ETag("http://localhost:8080/abc"))
+}
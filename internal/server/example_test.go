@@ -5,28 +5,30 @@ package server
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 
 	"github.com/cmrd-a/shortener/internal/service"
+	"github.com/cmrd-a/shortener/internal/storage"
 	"go.uber.org/zap"
 )
 
 type MockService struct{}
 
-func (m *MockService) Shorten(ctx context.Context, original string, userID int64) (short string, err error) {
+func (m *MockService) Shorten(ctx context.Context, original string, userID int64, opts service.ShortenOptions) (short string, err error) {
 	if original == "" {
 		return "", fmt.Errorf("empty URL")
 	}
 	return "http://localhost:8080/abc123", nil
 }
 
-func (m *MockService) ShortenBatch(ctx context.Context, userID int64, corrOrig map[string]string) (corrShort map[string]string, err error) {
+func (m *MockService) ShortenBatch(ctx context.Context, userID int64, corrItems map[string]service.BatchItem) (corrShort map[string]string, err error) {
 	corrShort = make(map[string]string)
-	for corr, orig := range corrOrig {
-		if orig == "" {
+	for corr, item := range corrItems {
+		if item.OriginalURL == "" {
 			return nil, fmt.Errorf("empty URL for correlation %s", corr)
 		}
 		corrShort[corr] = "http://localhost:8080/batch" + corr
@@ -34,20 +36,83 @@ func (m *MockService) ShortenBatch(ctx context.Context, userID int64, corrOrig m
 	return corrShort, nil
 }
 
-func (m *MockService) GetOriginal(ctx context.Context, short string) (original string, err error) {
+func (m *MockService) GetOriginal(ctx context.Context, short string, params service.TargetParams) (redirect service.Redirect, err error) {
+	return service.Redirect{URL: "https://example.com", StatusCode: http.StatusTemporaryRedirect}, nil
+}
+
+func (m *MockService) ShortURL(ctx context.Context, short string) (url string, err error) {
+	return "http://localhost:8080/" + short, nil
+}
+
+func (m *MockService) Preview(ctx context.Context, short string, params service.TargetParams) (url service.SvcURL, err error) {
+	return service.SvcURL{ShortURL: "http://localhost:8080/" + short, OriginalURL: "https://example.com"}, nil
+}
+
+func (m *MockService) Unlock(ctx context.Context, short, password string, params service.TargetParams) (original string, err error) {
 	return "https://example.com", nil
 }
 
+func (m *MockService) RecordClick(click service.Click) {}
+
+func (m *MockService) PurgeStats() service.PurgeStats {
+	return service.PurgeStats{}
+}
+
+func (m *MockService) GetClickStats(ctx context.Context, userID int64, short string) (storage.ClickStats, error) {
+	return storage.NewClickStats(), nil
+}
+
+func (m *MockService) UpdateURL(ctx context.Context, userID int64, short string, upd service.LinkUpdate) (service.SvcURL, error) {
+	return service.SvcURL{ShortURL: "http://localhost:8080/" + short, OriginalURL: "https://example.com"}, nil
+}
+
+func (m *MockService) GetHistory(ctx context.Context, userID int64, short string) ([]storage.Revision, error) {
+	return []storage.Revision{{Number: 1, ShortID: short, OriginalURL: "https://example.com", AuthorID: userID}}, nil
+}
+
+func (m *MockService) Rollback(ctx context.Context, userID int64, short string, revision int64) (service.SvcURL, error) {
+	return service.SvcURL{ShortURL: "http://localhost:8080/" + short, OriginalURL: "https://example.com"}, nil
+}
+
 func (m *MockService) Ping(ctx context.Context) (err error) {
 	return nil
 }
 
-func (m *MockService) GetUserURLs(ctx context.Context, userID int64) (urls []service.SvcURL, err error) {
-	return []service.SvcURL{}, nil
+func (m *MockService) GetUserURLs(ctx context.Context, userID int64, query storage.URLQuery) (service.URLPage, error) {
+	return service.URLPage{}, nil
+}
+
+func (m *MockService) SetTags(ctx context.Context, userID int64, short string, tags []string) (service.SvcURL, error) {
+	return service.SvcURL{ShortURL: "http://localhost:8080/" + short, OriginalURL: "https://example.com", Tags: tags}, nil
+}
+
+func (m *MockService) Import(ctx context.Context, userID int64, rows []service.ImportRow) ([]service.ImportResult, error) {
+	return nil, nil
+}
+
+func (m *MockService) ExportUserURLs(ctx context.Context, userID int64) iter.Seq2[service.ExportedURL, error] {
+	return func(yield func(service.ExportedURL, error) bool) {}
 }
 
-func (m *MockService) DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string) {
+func (m *MockService) GetUserTags(ctx context.Context, userID int64) ([]storage.TagCount, error) {
+	return nil, nil
+}
+
+func (m *MockService) DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string) (string, error) {
 	// Mock implementation - do nothing
+	return "job123", nil
+}
+
+func (m *MockService) GetDeletionJob(ctx context.Context, userID int64, jobID string) (service.DeletionJob, error) {
+	return service.DeletionJob{ID: jobID, UserID: userID, Status: service.DeletionQueued}, nil
+}
+
+func (m *MockService) GetTrash(ctx context.Context, userID int64) ([]service.TrashedURL, error) {
+	return []service.TrashedURL{}, nil
+}
+
+func (m *MockService) RestoreUserURLs(ctx context.Context, userID int64, shortIDs ...string) ([]string, error) {
+	return shortIDs, nil
 }
 
 func setupTestServer() *Server {
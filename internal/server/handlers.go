@@ -2,9 +2,18 @@ package server
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
+	"iter"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cmrd-a/shortener/internal/storage"
 
@@ -19,17 +28,49 @@ import (
 // Servicer defines the interface for URL shortening service operations.
 type Servicer interface {
 	// Сокращает ссылку
-	Shorten(ctx context.Context, original string, userID int64) (short string, err error)
+	Shorten(ctx context.Context, original string, userID int64, opts service.ShortenOptions) (short string, err error)
 	// Сокращает ссылки
-	ShortenBatch(ctx context.Context, userID int64, corrOrig map[string]string) (corrShort map[string]string, err error)
+	ShortenBatch(ctx context.Context, userID int64, corrItems map[string]service.BatchItem) (corrShort map[string]string, err error)
 	//Возвращает оригинальную ссылку
-	GetOriginal(ctx context.Context, short string) (original string, err error)
+	GetOriginal(ctx context.Context, short string, params service.TargetParams) (redirect service.Redirect, err error)
+	// Возвращает полную короткую ссылку
+	ShortURL(ctx context.Context, short string) (url string, err error)
+	// Возвращает ссылку для страницы предпросмотра
+	Preview(ctx context.Context, short string, params service.TargetParams) (url service.SvcURL, err error)
+	// Возвращает оригинальную ссылку, защищённую паролем
+	Unlock(ctx context.Context, short, password string, params service.TargetParams) (original string, err error)
 	// Проверяет соединение с базой данных
 	Ping(ctx context.Context) (err error)
-	// Возвращает все ссылки пользователя
-	GetUserURLs(ctx context.Context, userID int64) (urls []service.SvcURL, err error)
+	// Возвращает страницу ссылок пользователя, подходящих под запрос
+	GetUserURLs(ctx context.Context, userID int64, query storage.URLQuery) (page service.URLPage, err error)
+	// Заменяет метки ссылки пользователя
+	SetTags(ctx context.Context, userID int64, short string, tags []string) (url service.SvcURL, err error)
+	// Импортирует ссылки пользователя из файла
+	Import(ctx context.Context, userID int64, rows []service.ImportRow) (results []service.ImportResult, err error)
+	// Выгружает все ссылки пользователя
+	ExportUserURLs(ctx context.Context, userID int64) (urls iter.Seq2[service.ExportedURL, error])
+	// Возвращает метки ссылок пользователя с числом ссылок
+	GetUserTags(ctx context.Context, userID int64) (tags []storage.TagCount, err error)
 	// Удаляет ссылки пользователя
-	DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string)
+	DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string) (jobID string, err error)
+	// Возвращает задачу удаления ссылок пользователя
+	GetDeletionJob(ctx context.Context, userID int64, jobID string) (job service.DeletionJob, err error)
+	// Возвращает удалённые ссылки пользователя
+	GetTrash(ctx context.Context, userID int64) (urls []service.TrashedURL, err error)
+	// Восстанавливает удалённые ссылки пользователя
+	RestoreUserURLs(ctx context.Context, userID int64, shortIDs ...string) (restored []string, err error)
+	// Возвращает статистику очистки удалённых и истёкших ссылок
+	PurgeStats() (stats service.PurgeStats)
+	// Записывает переход по ссылке
+	RecordClick(click service.Click)
+	// Возвращает статистику переходов по ссылке пользователя
+	GetClickStats(ctx context.Context, userID int64, short string) (stats storage.ClickStats, err error)
+	// Изменяет ссылку пользователя
+	UpdateURL(ctx context.Context, userID int64, short string, upd service.LinkUpdate) (url service.SvcURL, err error)
+	// Возвращает историю изменений ссылки пользователя
+	GetHistory(ctx context.Context, userID int64, short string) (revisions []storage.Revision, err error)
+	// Откатывает ссылку пользователя к одной из ревизий
+	Rollback(ctx context.Context, userID int64, short string, revision int64) (url service.SvcURL, err error)
 }
 
 // AddLinkHandler returns an HTTP handler for shortening URLs via plain text body.
@@ -46,7 +87,11 @@ func AddLinkHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 		userID := middleware.GetUserID(req.Context())
-		shortLink, err := svc.Shorten(req.Context(), originalLink, userID)
+		shortLink, err := svc.Shorten(req.Context(), originalLink, userID, service.ShortenOptions{})
+		if status := shortenErrorStatus(err); status != 0 {
+			http.Error(res, err.Error(), status)
+			return
+		}
 		var alreadyExistError *service.OriginalExistError
 		if errors.As(err, &alreadyExistError) {
 			res.WriteHeader(http.StatusConflict)
@@ -68,24 +113,165 @@ func AddLinkHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 }
 
 // GetLinkHandler returns an HTTP handler for redirecting shortened URLs to their original URLs.
+// The redirect status code is chosen per link, and only permanent redirects may be cached.
+// Password-protected links get a password form instead of a redirect,
+// and links to blocked URLs get a 451 interstitial.
+// A short ID followed by '+' or the preview=1 query parameter shows a preview page with the
+// destination instead of redirecting.
+// The query and the path segments after the short ID are merged into the original URL, see targetParams.
 func GetLinkHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	return func(res http.ResponseWriter, req *http.Request) {
-		ID := chi.URLParam(req, "linkId")
+		ID, preview := strings.CutSuffix(chi.URLParam(req, "linkId"), "+")
 		if len(ID) == 0 {
 			http.Error(res, "url is empty", http.StatusBadRequest)
 			return
 		}
-		original, err := svc.GetOriginal(req.Context(), ID)
+		if p, err := strconv.ParseBool(req.URL.Query().Get("preview")); err == nil && p {
+			preview = true
+		}
+		if preview {
+			previewLink(res, req, svc, ID)
+			return
+		}
+		redirect, err := svc.GetOriginal(req.Context(), ID, targetParams(req))
+		if errors.Is(err, service.ErrPasswordRequired) {
+			renderPage(res, http.StatusOK, "password.html", passwordPage{})
+			return
+		}
+		if errors.Is(err, service.ErrURLBlocked) {
+			renderPage(res, http.StatusUnavailableForLegalReasons, "blocked.html", nil)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), linkErrorStatus(err))
+			return
+		}
+		svc.RecordClick(newClick(req, ID))
+		if redirect.MaxAge > 0 {
+			res.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(redirect.MaxAge.Seconds())))
+		} else {
+			res.Header().Set("Cache-Control", "private, no-store")
+		}
+		http.Redirect(res, req, redirect.URL, redirect.StatusCode)
+	}
+}
+
+// previewLink renders a page with the destination of the link and a button that follows it.
+// Protected and blocked links get the same pages as on redirect, so their destination is not shown.
+func previewLink(res http.ResponseWriter, req *http.Request, svc Servicer, ID string) {
+	params := targetParams(req)
+	link, err := svc.Preview(req.Context(), ID, params)
+	if errors.Is(err, service.ErrPasswordRequired) {
+		renderPage(res, http.StatusOK, "password.html", passwordPage{})
+		return
+	}
+	if errors.Is(err, service.ErrURLBlocked) {
+		renderPage(res, http.StatusUnavailableForLegalReasons, "blocked.html", nil)
+		return
+	}
+	if err != nil {
+		http.Error(res, err.Error(), linkErrorStatus(err))
+		return
+	}
+	continueURL := "/" + url.PathEscape(ID)
+	for _, segment := range params.Segments {
+		continueURL += "/" + url.PathEscape(segment)
+	}
+	if len(params.Query) > 0 {
+		continueURL += "?" + params.Query.Encode()
+	}
+	page := previewPage{
+		OriginalURL: link.OriginalURL,
+		Host:        link.OriginalURL,
+		CreatedAt:   link.CreatedAt,
+		ContinueURL: continueURL,
+	}
+	if u, err := url.Parse(link.OriginalURL); err == nil && u.Host != "" {
+		page.Host = u.Hostname()
+	}
+	renderPage(res, http.StatusOK, "preview.html", page)
+}
+
+// UnlockLinkHandler returns an HTTP handler that checks the password POSTed from the form
+// of a protected link and redirects to the original URL if it matches.
+// It always answers with 303 so that the browser does not resend the password to the target.
+func UnlockLinkHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		// The password form of a preview is posted to the preview URL.
+		ID := strings.TrimSuffix(chi.URLParam(req, "linkId"), "+")
+		if len(ID) == 0 {
+			http.Error(res, "url is empty", http.StatusBadRequest)
+			return
+		}
+		err := req.ParseForm()
 		if err != nil {
-			if errors.Is(err, storage.ErrURLIsDeleted) {
-				http.Error(res, err.Error(), http.StatusGone)
-				return
-			}
 			http.Error(res, err.Error(), http.StatusBadRequest)
 			return
 		}
-		http.Redirect(res, req, original, http.StatusTemporaryRedirect)
+		original, err := svc.Unlock(req.Context(), ID, req.PostForm.Get("password"), targetParams(req))
+		if errors.Is(err, service.ErrWrongPassword) {
+			renderPage(res, http.StatusForbidden, "password.html", passwordPage{WrongPassword: true})
+			return
+		}
+		if errors.Is(err, service.ErrURLBlocked) {
+			renderPage(res, http.StatusUnavailableForLegalReasons, "blocked.html", nil)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), linkErrorStatus(err))
+			return
+		}
+		svc.RecordClick(newClick(req, ID))
+		http.Redirect(res, req, original, http.StatusSeeOther)
+	}
+}
+
+// targetParams collects the parts of a request for a short link that are merged into its original URL:
+// the query without the preview parameter and the non-empty path segments after the short ID.
+func targetParams(req *http.Request) service.TargetParams {
+	query := req.URL.Query()
+	query.Del("preview")
+	params := service.TargetParams{Query: query}
+	for segment := range strings.SplitSeq(chi.URLParam(req, "*"), "/") {
+		if segment == "" {
+			continue
+		}
+		// chi routes on the escaped path when it differs from the decoded one.
+		if req.URL.RawPath != "" {
+			if s, err := url.PathUnescape(segment); err == nil {
+				segment = s
+			}
+		}
+		params.Segments = append(params.Segments, segment)
+	}
+	return params
+}
+
+// newClick collects the click details of a redirect request.
+func newClick(req *http.Request, shortID string) service.Click {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	return service.Click{
+		ShortID:   shortID,
+		Referrer:  req.Referer(),
+		UserAgent: req.UserAgent(),
+		IP:        ip,
+	}
+}
+
+// linkErrorStatus maps errors of resolving a short link to HTTP status codes.
+func linkErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrURLNotFound) {
+		return http.StatusNotFound
 	}
+	if errors.Is(err, storage.ErrURLIsDeleted) ||
+		errors.Is(err, storage.ErrURLIsExpired) ||
+		errors.Is(err, storage.ErrURLClicksExhausted) {
+		return http.StatusGone
+	}
+	return http.StatusBadRequest
 }
 
 // ShortenHandler returns an HTTP handler for shortening URLs via JSON request body.
@@ -102,7 +288,11 @@ func ShortenHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 		userID := middleware.GetUserID(req.Context())
-		shortLink, err := svc.Shorten(req.Context(), reqJSON.URL, userID)
+		shortLink, err := svc.Shorten(req.Context(), reqJSON.URL, userID, shortenOptions(reqJSON.LinkOptions))
+		if status := shortenErrorStatus(err); status != 0 {
+			http.Error(res, err.Error(), status)
+			return
+		}
 		var alreadyExistError *service.OriginalExistError
 		res.Header().Set("Content-Type", "application/json")
 		if errors.As(err, &alreadyExistError) {
@@ -137,6 +327,48 @@ func ShortenHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+// shortenOptions builds service options from the optional fields of a shorten request.
+func shortenOptions(o LinkOptions) service.ShortenOptions {
+	opts := service.ShortenOptions{
+		Alias:        o.Alias,
+		ExpiresIn:    time.Duration(o.ExpiresIn) * time.Second,
+		MaxClicks:    o.MaxClicks,
+		Password:     o.Password,
+		RedirectType: o.RedirectType,
+		QueryPolicy:  o.QueryPolicy,
+		Title:        o.Title,
+		Notes:        o.Notes,
+		Tags:         o.Tags,
+	}
+	if o.ExpiresAt != nil {
+		opts.ExpiresAt = *o.ExpiresAt
+	}
+	return opts
+}
+
+// shortenErrorStatus maps the client errors of Shorten and ShortenBatch to HTTP status codes.
+// It returns zero for other errors.
+func shortenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidURL),
+		errors.Is(err, service.ErrInvalidAlias),
+		errors.Is(err, service.ErrInvalidExpiry),
+		errors.Is(err, service.ErrInvalidMaxClicks),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrInvalidRedirectType),
+		errors.Is(err, service.ErrInvalidQueryPolicy),
+		errors.Is(err, service.ErrInvalidTitle),
+		errors.Is(err, service.ErrInvalidNotes),
+		errors.Is(err, service.ErrInvalidTag):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrURLBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrAliasTaken):
+		return http.StatusConflict
+	}
+	return 0
+}
+
 // ShortenBatchHandler returns an HTTP handler for shortening multiple URLs in a single request.
 func ShortenBatchHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	return func(res http.ResponseWriter, req *http.Request) {
@@ -150,24 +382,31 @@ func ShortenBatchHandler(svc Servicer) func(http.ResponseWriter, *http.Request)
 			http.Error(res, err.Error(), http.StatusBadRequest)
 			return
 		}
-		corrOrig := make(map[string]string, len(reqJSON))
+		corrItems := make(map[string]service.BatchItem, len(reqJSON))
 		for _, reqItem := range reqJSON {
 			if reqItem.OriginalURL == "" || reqItem.CorrelationID == "" {
 				http.Error(res, "original_url or correlation_id is empty", http.StatusBadRequest)
 				return
 			}
-			if _, ok := corrOrig[reqItem.CorrelationID]; ok {
+			if _, ok := corrItems[reqItem.CorrelationID]; ok {
 				http.Error(res, "duplicated correlation_id"+reqItem.CorrelationID, http.StatusBadRequest)
 			}
-			for _, original := range corrOrig {
-				if original == reqItem.OriginalURL {
+			for _, item := range corrItems {
+				if item.OriginalURL == reqItem.OriginalURL {
 					http.Error(res, "duplicates original_url"+reqItem.OriginalURL, http.StatusBadRequest)
 				}
 			}
-			corrOrig[reqItem.CorrelationID] = reqItem.OriginalURL
+			corrItems[reqItem.CorrelationID] = service.BatchItem{
+				OriginalURL:    reqItem.OriginalURL,
+				ShortenOptions: shortenOptions(reqItem.LinkOptions),
+			}
 		}
 		userID := middleware.GetUserID(req.Context())
-		corrShort, err := svc.ShortenBatch(req.Context(), userID, corrOrig)
+		corrShort, err := svc.ShortenBatch(req.Context(), userID, corrItems)
+		if status := shortenErrorStatus(err); status != 0 {
+			http.Error(res, err.Error(), status)
+			return
+		}
 		if err != nil {
 			http.Error(res, err.Error(), http.StatusInternalServerError)
 			return
@@ -207,7 +446,15 @@ func PingHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
-// GetUserURLsHandler returns an HTTP handler for retrieving all URLs created by a user.
+// GetUserURLsHandler returns an HTTP handler for retrieving a page of the URLs created by a user.
+// The query parameters are:
+//   - tag, domain: keep the URLs carrying the tag or pointing to the domain and its subdomains;
+//   - created_after, created_before: RFC 3339 times, keep the URLs created in [created_after, created_before);
+//   - sort: created_at (default) or short_url, prefixed with '-' for the descending order;
+//   - limit: the page size, see service.DefaultPageSize and service.MaxPageSize;
+//   - cursor: the X-Next-Cursor header of the previous page.
+//
+// The X-Next-Cursor header is set unless the page is the last one.
 func GetUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	return func(res http.ResponseWriter, req *http.Request) {
 		userID := middleware.GetUserID(req.Context())
@@ -216,7 +463,321 @@ func GetUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		urls, err := svc.GetUserURLs(req.Context(), userID)
+		query, err := userURLsQuery(req.URL.Query())
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeUserURLs(res, req, svc, userID, query)
+	}
+}
+
+// SearchUserURLsHandler returns an HTTP handler for finding the URLs of a user whose original URL,
+// title, notes or one of the tags contains the q query parameter, case-insensitively.
+// It takes the other query parameters of GetUserURLsHandler and pages the same way.
+func SearchUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := req.URL.Query()
+		if strings.TrimSpace(params.Get("q")) == "" {
+			http.Error(res, "q is required", http.StatusBadRequest)
+			return
+		}
+		query, err := userURLsQuery(params)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.Search = params.Get("q")
+		writeUserURLs(res, req, svc, userID, query)
+	}
+}
+
+// writeUserURLs writes a page of the URLs of a user matching the query as a JSON response.
+func writeUserURLs(res http.ResponseWriter, req *http.Request, svc Servicer, userID int64, query storage.URLQuery) {
+	page, err := svc.GetUserURLs(req.Context(), userID, query)
+	if errors.Is(err, service.ErrInvalidTag) || errors.Is(err, service.ErrInvalidQuery) {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if page.NextCursor != "" {
+		res.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	if len(page.URLs) == 0 {
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+
+	resJSON := make(GetUserURLsResponse, 0, len(page.URLs))
+	for _, u := range page.URLs {
+		resJSON = append(resJSON, userURLItem(u))
+	}
+
+	resBytes, err := resJSON.MarshalJSON()
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, err = res.Write(resBytes)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportFlushEvery is how many exported URLs are written between flushes to the client.
+const exportFlushEvery = 100
+
+// ExportUserURLsHandler returns an HTTP handler streaming all URLs of a user with their tags and click counts.
+// The format query parameter selects csv (default) or jsonl (JSON Lines, see ExportItem).
+// If the repository fails after the export has started, the response is aborted, so that a truncated
+// export cannot be mistaken for a complete one.
+func ExportUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var write func(service.ExportedURL) error
+		var start func() error
+		switch format := req.URL.Query().Get("format"); format {
+		case "", "csv":
+			w := csv.NewWriter(res)
+			start = func() error {
+				return w.Write([]string{"short_url", "original_url", "created_at", "tags", "clicks"})
+			}
+			write = func(u service.ExportedURL) error {
+				var createdAt string
+				if u.CreatedAt != nil {
+					createdAt = u.CreatedAt.UTC().Format(time.RFC3339)
+				}
+				err := w.Write([]string{u.ShortURL, u.OriginalURL, createdAt, strings.Join(u.Tags, ","), strconv.FormatInt(u.Clicks, 10)})
+				w.Flush()
+				return err
+			}
+			res.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			res.Header().Set("Content-Disposition", `attachment; filename="urls.csv"`)
+		case "jsonl":
+			start = func() error { return nil }
+			write = func(u service.ExportedURL) error {
+				line, err := ExportItem{
+					ShortURL:    u.ShortURL,
+					OriginalURL: u.OriginalURL,
+					CreatedAt:   u.CreatedAt,
+					Tags:        u.Tags,
+					Clicks:      u.Clicks,
+				}.MarshalJSON()
+				if err != nil {
+					return err
+				}
+				_, err = res.Write(append(line, '\n'))
+				return err
+			}
+			res.Header().Set("Content-Type", "application/jsonl")
+			res.Header().Set("Content-Disposition", `attachment; filename="urls.jsonl"`)
+		default:
+			http.Error(res, fmt.Sprintf("unknown format %q, want csv or jsonl", format), http.StatusBadRequest)
+			return
+		}
+
+		rc := http.NewResponseController(res)
+		started := false
+		written := 0
+		for u, err := range svc.ExportUserURLs(req.Context(), userID) {
+			if err == nil && !started {
+				started = true
+				res.WriteHeader(http.StatusOK)
+				err = start()
+			}
+			if err == nil {
+				err = write(u)
+			}
+			if err != nil && !started {
+				res.Header().Del("Content-Disposition")
+				http.Error(res, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err != nil {
+				panic(http.ErrAbortHandler)
+			}
+			if written++; written%exportFlushEvery == 0 {
+				_ = rc.Flush()
+			}
+		}
+		if !started {
+			res.WriteHeader(http.StatusOK)
+			if err := start(); err != nil {
+				panic(http.ErrAbortHandler)
+			}
+		}
+	}
+}
+
+// userURLsQuery parses the query parameters of GetUserURLsHandler.
+func userURLsQuery(params url.Values) (storage.URLQuery, error) {
+	query := storage.URLQuery{
+		Tag:    params.Get("tag"),
+		Domain: params.Get("domain"),
+	}
+	var err error
+	for name, t := range map[string]*time.Time{"created_after": &query.CreatedAfter, "created_before": &query.CreatedBefore} {
+		if v := params.Get(name); v != "" {
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				return query, fmt.Errorf("%w: %s must be an RFC 3339 time", service.ErrInvalidQuery, name)
+			}
+		}
+	}
+	sort := params.Get("sort")
+	query.Desc = strings.HasPrefix(sort, "-")
+	query.Sort = storage.URLSort(strings.TrimPrefix(sort, "-"))
+	if v := params.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit <= 0 {
+			return query, fmt.Errorf("%w: limit must be a positive integer", service.ErrInvalidQuery)
+		}
+	}
+	if v := params.Get("cursor"); v != "" {
+		if query.After, err = service.DecodeCursor(query, v); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// userURLItem converts a service URL to its JSON representation.
+func userURLItem(u service.SvcURL) GetUserURLsResponseItem {
+	return GetUserURLsResponseItem{
+		ShortURL:     u.ShortURL,
+		OriginalURL:  u.OriginalURL,
+		ExpiresAt:    u.ExpiresAt,
+		ClicksLeft:   u.ClicksLeft,
+		RedirectType: u.RedirectType,
+		QueryPolicy:  u.QueryPolicy,
+		Title:        u.Title,
+		Notes:        u.Notes,
+		Tags:         u.Tags,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+// DeleteUserURLsHandler returns an HTTP handler for marking user URLs as deleted.
+// It responds with the ID of the deletion job and its location, see DeletionJobHandler,
+// or with 503 and Retry-After if the deletion queue is saturated.
+func DeleteUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var reqJSON DeleteUserURLsRequest
+		err := easyjson.UnmarshalFromReader(req.Body, &reqJSON)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jobID, err := svc.DeleteUserURLs(req.Context(), userID, reqJSON...)
+		var fullErr *service.DeletionQueueFullError
+		if errors.As(err, &fullErr) {
+			retryAfter := int64(math.Ceil(fullErr.RetryAfter.Seconds()))
+			res.Header().Set("Retry-After", strconv.FormatInt(max(retryAfter, 1), 10))
+			http.Error(res, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, service.ErrTooManyDeletions) {
+			http.Error(res, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, service.ErrShuttingDown) {
+			http.Error(res, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resBytes, err := DeleteUserURLsResponse{JobID: jobID}.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("Location", "/api/user/urls/deletions/"+jobID)
+		res.WriteHeader(http.StatusAccepted)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// DeletionJobHandler returns an HTTP handler for tracking a deletion job of a user.
+func DeletionJobHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		job, err := svc.GetDeletionJob(req.Context(), userID, chi.URLParam(req, "jobId"))
+		if errors.Is(err, service.ErrDeletionJobNotFound) {
+			http.Error(res, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resJSON := DeletionJobResponse{
+			JobID:     job.ID,
+			Status:    string(job.Status),
+			CreatedAt: job.CreatedAt,
+			URLs:      make([]DeletionJobResponseItem, len(job.Items)),
+		}
+		if !job.AppliedAt.IsZero() {
+			resJSON.AppliedAt = &job.AppliedAt
+		}
+		for i, item := range job.Items {
+			resJSON.URLs[i] = DeletionJobResponseItem{ID: item.ShortID, Outcome: string(item.Outcome)}
+		}
+
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// TrashHandler returns an HTTP handler for listing the deleted URLs of a user.
+func TrashHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		urls, err := svc.GetTrash(req.Context(), userID)
 		if err != nil {
 			http.Error(res, err.Error(), http.StatusInternalServerError)
 			return
@@ -227,20 +788,119 @@ func GetUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
+		resJSON := make(TrashResponse, 0, len(urls))
+		for _, u := range urls {
+			resJSON = append(resJSON, TrashResponseItem{
+				ShortURL:      u.ShortURL,
+				OriginalURL:   u.OriginalURL,
+				DeletedAt:     u.DeletedAt,
+				RestoreBefore: u.RestoreBefore,
+			})
+		}
+
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		res.Header().Set("Content-Type", "application/json")
 		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
 
-		resJSON := make(GetUserURLsResponse, 0)
-		for _, u := range urls {
-			item := GetUserURLsResponseItem{ShortURL: u.ShortURL, OriginalURL: u.OriginalURL}
-			resJSON = append(resJSON, item)
+// RestoreUserURLsHandler returns an HTTP handler for restoring deleted URLs of a user.
+// It responds with the short IDs that were restored.
+func RestoreUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var reqJSON RestoreUserURLsRequest
+		err := easyjson.UnmarshalFromReader(req.Body, &reqJSON)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		restored, err := svc.RestoreUserURLs(req.Context(), userID, reqJSON...)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resBytes, err := RestoreUserURLsResponse(restored).MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// PurgeStatsHandler returns an HTTP handler for retrieving the hard purge statistics.
+func PurgeStatsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		stats := svc.PurgeStats()
+		resJSON := PurgeStatsResponse{
+			Retention:    int64(stats.Retention / time.Second),
+			LastDeleted:  stats.Last.Deleted,
+			LastExpired:  stats.Last.Expired,
+			TotalDeleted: stats.Total.Deleted,
+			TotalExpired: stats.Total.Expired,
+		}
+		if !stats.LastRunAt.IsZero() {
+			resJSON.LastRunAt = &stats.LastRunAt
+		}
+
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// ClickStatsHandler returns an HTTP handler for retrieving click statistics of a user's URL.
+func ClickStatsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
 		}
 
+		stats, err := svc.GetClickStats(req.Context(), userID, chi.URLParam(req, "linkId"))
+		if errors.Is(err, storage.ErrURLNotFound) {
+			http.Error(res, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resJSON := ClickStatsResponse{
+			Total:      stats.Total,
+			ByDay:      stats.ByDay,
+			ByReferrer: stats.ByReferrer,
+			ByBrowser:  stats.ByBrowser,
+		}
 		resBytes, err := resJSON.MarshalJSON()
 		if err != nil {
 			http.Error(res, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
 		_, err = res.Write(resBytes)
 		if err != nil {
 			http.Error(res, err.Error(), http.StatusInternalServerError)
@@ -249,8 +909,9 @@ func GetUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
-// DeleteUserURLsHandler returns an HTTP handler for marking user URLs as deleted.
-func DeleteUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+// UpdateURLHandler returns an HTTP handler for changing the target, redirect type, query policy, expiry,
+// title or notes of a user's URL.
+func UpdateURLHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
 	return func(res http.ResponseWriter, req *http.Request) {
 		userID := middleware.GetUserID(req.Context())
 		if userID == 0 {
@@ -258,14 +919,195 @@ func DeleteUserURLsHandler(svc Servicer) func(http.ResponseWriter, *http.Request
 			return
 		}
 
-		var reqJSON DeleteUserURLsRequest
+		var reqJSON UpdateURLRequest
 		err := easyjson.UnmarshalFromReader(req.Body, &reqJSON)
 		if err != nil {
 			http.Error(res, err.Error(), http.StatusBadRequest)
 			return
 		}
+		upd := service.LinkUpdate{
+			OriginalURL:  reqJSON.OriginalURL,
+			RedirectType: reqJSON.RedirectType,
+			QueryPolicy:  reqJSON.QueryPolicy,
+			Title:        reqJSON.Title,
+			Notes:        reqJSON.Notes,
+			ExpiresIn:    time.Duration(reqJSON.ExpiresIn) * time.Second,
+			NoExpiry:     reqJSON.NoExpiry,
+		}
+		if reqJSON.ExpiresAt != nil {
+			upd.ExpiresAt = *reqJSON.ExpiresAt
+		}
 
-		svc.DeleteUserURLs(req.Context(), userID, reqJSON...)
-		res.WriteHeader(http.StatusAccepted)
+		u, err := svc.UpdateURL(req.Context(), userID, chi.URLParam(req, "linkId"), upd)
+		if err != nil {
+			http.Error(res, err.Error(), updateErrorStatus(err))
+			return
+		}
+		writeUserURL(res, u)
+	}
+}
+
+// HistoryHandler returns an HTTP handler for listing the revisions of a user's URL.
+func HistoryHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		revisions, err := svc.GetHistory(req.Context(), userID, chi.URLParam(req, "linkId"))
+		if err != nil {
+			http.Error(res, err.Error(), updateErrorStatus(err))
+			return
+		}
+
+		resJSON := make(HistoryResponse, 0, len(revisions))
+		for _, rev := range revisions {
+			item := HistoryResponseItem{
+				Revision:     rev.Number,
+				OriginalURL:  rev.OriginalURL,
+				RedirectType: rev.RedirectType,
+				QueryPolicy:  rev.QueryPolicy,
+				ExpiresAt:    rev.ExpiresAt,
+				Title:        rev.Title,
+				Notes:        rev.Notes,
+				AuthorID:     rev.AuthorID,
+			}
+			if !rev.CreatedAt.IsZero() {
+				item.CreatedAt = &rev.CreatedAt
+			}
+			resJSON = append(resJSON, item)
+		}
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, err = res.Write(resBytes)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// RollbackHandler returns an HTTP handler for restoring a user's URL from one of its revisions.
+func RollbackHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var reqJSON RollbackRequest
+		err := easyjson.UnmarshalFromReader(req.Body, &reqJSON)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		u, err := svc.Rollback(req.Context(), userID, chi.URLParam(req, "linkId"), reqJSON.Revision)
+		if err != nil {
+			http.Error(res, err.Error(), updateErrorStatus(err))
+			return
+		}
+		writeUserURL(res, u)
+	}
+}
+
+// SetTagsHandler returns an HTTP handler for replacing the tags of a user's URL.
+func SetTagsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var reqJSON SetTagsRequest
+		err := easyjson.UnmarshalFromReader(req.Body, &reqJSON)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		u, err := svc.SetTags(req.Context(), userID, chi.URLParam(req, "linkId"), reqJSON)
+		if err != nil {
+			http.Error(res, err.Error(), updateErrorStatus(err))
+			return
+		}
+		writeUserURL(res, u)
+	}
+}
+
+// UserTagsHandler returns an HTTP handler for listing the tags of a user's URLs with their link counts.
+func UserTagsHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		tags, err := svc.GetUserTags(req.Context(), userID)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if len(tags) == 0 {
+			res.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		resJSON := make(TagsResponse, 0, len(tags))
+		for _, t := range tags {
+			resJSON = append(resJSON, TagsResponseItem{Tag: t.Tag, Count: t.Count})
+		}
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// updateErrorStatus maps the errors of changing a user's URL to HTTP status codes.
+func updateErrorStatus(err error) int {
+	var alreadyExistError *service.OriginalExistError
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound), errors.Is(err, service.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrEmptyUpdate):
+		return http.StatusBadRequest
+	case errors.As(err, &alreadyExistError):
+		return http.StatusConflict
+	}
+	if status := shortenErrorStatus(err); status != 0 {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// writeUserURL writes a user's URL as a JSON response.
+func writeUserURL(res http.ResponseWriter, u service.SvcURL) {
+	resBytes, err := userURLItem(u).MarshalJSON()
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	_, err = res.Write(resBytes)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
 	}
 }
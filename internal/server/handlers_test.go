@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cmrd-a/shortener/internal/config"
 	"github.com/cmrd-a/shortener/internal/logger"
@@ -42,6 +47,8 @@ func TestAddLinkHandler(t *testing.T) {
 		{name: "empty_body", params: params{method: http.MethodPost, url: "/", body: ""}, want: want{stausCode: http.StatusBadRequest}},
 		{name: "invalid_method", params: params{method: http.MethodGet, url: "/", body: "https://example.com"}, want: want{stausCode: http.StatusMethodNotAllowed}},
 		{name: "long_url", params: params{method: http.MethodPost, url: "/", body: "https://verylongexampleurl.com/with/many/path/segments/and/query/parameters?param1=value1&param2=value2"}, want: want{stausCode: http.StatusCreated}},
+		{name: "javascript_url", params: params{method: http.MethodPost, url: "/", body: "javascript:alert(1)"}, want: want{stausCode: http.StatusBadRequest}},
+		{name: "not_a_url", params: params{method: http.MethodPost, url: "/", body: "just some text"}, want: want{stausCode: http.StatusBadRequest}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -83,6 +90,7 @@ func TestShortenHandler(t *testing.T) {
 		{name: "happy_path_with_compress", reqBody: "{\"url\": \"https://microservices.io\"}", resStatus: http.StatusCreated, resLen: 10, compress: true},
 		{name: "empty_body", reqBody: "", resStatus: http.StatusBadRequest},
 		{name: "empty_url", reqBody: "{\"url\": \"\"}", resStatus: http.StatusBadRequest},
+		{name: "too_large_decompressed", reqBody: "{\"url\": \"https://grpc.io\"" + strings.Repeat(" ", 17<<20) + "}", resStatus: http.StatusBadRequest, compress: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -115,6 +123,397 @@ func TestShortenHandler(t *testing.T) {
 	}
 }
 
+func TestShortenHandlerAlias(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqBody   string
+		resStatus int
+		location  string
+	}{
+		{name: "alias_created", reqBody: `{"url": "https://spring.example.com", "alias": "spring-sale"}`, resStatus: http.StatusCreated, location: "https://spring.example.com"},
+		{name: "alias_taken", reqBody: `{"url": "https://autumn.example.com", "alias": "spring-sale"}`, resStatus: http.StatusConflict},
+		{name: "alias_reserved", reqBody: `{"url": "https://ping.example.com", "alias": "ping"}`, resStatus: http.StatusBadRequest},
+		{name: "alias_invalid_chars", reqBody: `{"url": "https://chars.example.com", "alias": "spring sale"}`, resStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			res := executeRequest(req, server)
+
+			assert.Equal(t, tt.resStatus, res.Code)
+			if tt.location != "" {
+				assert.Contains(t, res.Body.String(), "/spring-sale")
+				res := executeRequest(httptest.NewRequest(http.MethodGet, "/spring-sale", nil), server)
+				assert.Equal(t, tt.location, res.Header().Get("location"))
+			}
+		})
+	}
+}
+
+func TestShortenHandlerExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqBody   string
+		resStatus int
+	}{
+		{name: "expires_in", reqBody: `{"url": "https://ttl.example.com", "expires_in": 3600}`, resStatus: http.StatusCreated},
+		{name: "expires_at", reqBody: `{"url": "https://at.example.com", "expires_at": "2999-01-01T00:00:00Z"}`, resStatus: http.StatusCreated},
+		{name: "expires_at_in_past", reqBody: `{"url": "https://past.example.com", "expires_at": "2000-01-01T00:00:00Z"}`, resStatus: http.StatusBadRequest},
+		{name: "both_set", reqBody: `{"url": "https://both.example.com", "expires_in": 60, "expires_at": "2999-01-01T00:00:00Z"}`, resStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			res := executeRequest(req, server)
+
+			assert.Equal(t, tt.resStatus, res.Code)
+		})
+	}
+}
+
+func TestGetLinkHandlerExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	err := repo.Add(ctx, storage.StoredURL{ShortID: "expired-link", OriginalURL: "https://expired.example.com", ExpiresAt: &past})
+	assert.NoError(t, err)
+
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/expired-link", nil), server)
+	assert.Equal(t, http.StatusGone, res.Code)
+}
+
+func TestGetLinkHandlerMaxClicks(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://once.example.com", "alias": "one-time", "max_clicks": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	listReq := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	listReq.AddCookie(authCookie)
+	list := executeRequest(listReq, server)
+	assert.Contains(t, list.Body.String(), `"clicks_left":1`)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/one-time", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/one-time", nil), server)
+	assert.Equal(t, http.StatusGone, res.Code)
+
+	list = executeRequest(listReq, server)
+	assert.Contains(t, list.Body.String(), `"clicks_left":0`)
+}
+
+func TestGetLinkHandlerPassword(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://secret.example.com", "alias": "secret-link", "password": "hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/secret-link", nil), server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, res.Body.String(), `name="password"`)
+	assert.NotContains(t, res.Body.String(), "secret.example.com")
+
+	unlock := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/secret-link", strings.NewReader("password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return executeRequest(req, server)
+	}
+
+	res = unlock("wrong")
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	assert.Contains(t, res.Body.String(), "Wrong password")
+	assert.NotContains(t, res.Body.String(), "secret.example.com")
+
+	res = unlock("hunter2")
+	assert.Equal(t, http.StatusSeeOther, res.Code)
+	assert.Equal(t, "https://secret.example.com", res.Header().Get("location"))
+}
+
+func TestClickStatsHandler(t *testing.T) {
+	svc := service.NewURLService(generator, cfg.BaseURL, storage.NewInMemoryRepository(), service.WithClickFlushInterval(10*time.Millisecond))
+	srv := NewServer(zl, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://stats.example.com", "alias": "with-stats"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, srv)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	follow := httptest.NewRequest(http.MethodGet, "/with-stats", nil)
+	follow.Header.Set("Referer", "https://blog.example.org/post")
+	follow.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0")
+	res = executeRequest(follow, srv)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+
+	statsReq := httptest.NewRequest(http.MethodGet, "/api/user/urls/with-stats/stats", nil)
+	statsReq.AddCookie(authCookie)
+	assert.Eventually(t, func() bool {
+		res = executeRequest(statsReq, srv)
+		return res.Code == http.StatusOK && strings.Contains(res.Body.String(), `"total":1`)
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, res.Body.String(), `"blog.example.org":1`)
+	assert.Contains(t, res.Body.String(), `"Firefox":1`)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/api/user/urls/with-stats/stats", nil), srv)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+
+	missingReq := httptest.NewRequest(http.MethodGet, "/api/user/urls/missing/stats", nil)
+	missingReq.AddCookie(authCookie)
+	res = executeRequest(missingReq, srv)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+type hostBlocker string
+
+func (b hostBlocker) Blocked(rawURL string) (string, bool) {
+	return string(b), strings.Contains(rawURL, string(b))
+}
+
+func TestGetLinkHandlerBlocked(t *testing.T) {
+	blockedRepo := storage.NewInMemoryRepository()
+	err := blockedRepo.Add(ctx, storage.StoredURL{ShortID: "blocked", OriginalURL: "https://evil.example/", UserID: 1})
+	assert.NoError(t, err)
+	srv := NewServer(zl, service.NewURLService(generator, cfg.BaseURL, blockedRepo, service.WithBlocker(hostBlocker("evil.example"))))
+
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/blocked", nil), srv)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, res.Code)
+	assert.NotContains(t, res.Body.String(), "evil.example")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://evil.example/new"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res = executeRequest(req, srv)
+	assert.Equal(t, http.StatusForbidden, res.Code)
+}
+
+func TestGetLinkHandlerRedirectType(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirectType int
+		wantStatus   int
+		wantCache    string
+	}{
+		{name: "default", redirectType: 0, wantStatus: http.StatusTemporaryRedirect, wantCache: "private, no-store"},
+		{name: "found", redirectType: http.StatusFound, wantStatus: http.StatusFound, wantCache: "private, no-store"},
+		{name: "moved", redirectType: http.StatusMovedPermanently, wantStatus: http.StatusMovedPermanently, wantCache: "public, max-age=86400"},
+		{name: "permanent", redirectType: http.StatusPermanentRedirect, wantStatus: http.StatusPermanentRedirect, wantCache: "public, max-age=86400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"url": "https://%s.redirect.example/", "alias": "redirect-%s", "redirect_type": %d}`, tt.name, tt.name, tt.redirectType)
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			res := executeRequest(req, server)
+			assert.Equal(t, http.StatusCreated, res.Code)
+
+			res = executeRequest(httptest.NewRequest(http.MethodGet, "/redirect-"+tt.name, nil), server)
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantCache, res.Header().Get("Cache-Control"))
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://bad.redirect.example/", "redirect_type": 200}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestUpdateURLHandlers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://before.example.com", "alias": "editable"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/user/urls/editable", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = patch(`{}`)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	res = patch(`{"original_url": "https://after.example.com", "redirect_type": 308}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"original_url":"https://after.example.com"`)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/editable", nil), server)
+	assert.Equal(t, http.StatusPermanentRedirect, res.Code)
+	assert.Equal(t, "https://after.example.com", res.Header().Get("location"))
+
+	historyReq := httptest.NewRequest(http.MethodGet, "/api/user/urls/editable/history", nil)
+	historyReq.AddCookie(authCookie)
+	res = executeRequest(historyReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"revision":1,"original_url":"https://before.example.com"`)
+	assert.Contains(t, res.Body.String(), `"revision":2,"original_url":"https://after.example.com"`)
+
+	rollbackReq := httptest.NewRequest(http.MethodPost, "/api/user/urls/editable/rollback", strings.NewReader(`{"revision": 1}`))
+	rollbackReq.Header.Set("Content-Type", "application/json")
+	rollbackReq.AddCookie(authCookie)
+	res = executeRequest(rollbackReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/editable", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+	assert.Equal(t, "https://before.example.com", res.Header().Get("location"))
+
+	foreign := httptest.NewRequest(http.MethodPatch, "/api/user/urls/editable", strings.NewReader(`{"original_url": "https://hijack.example.com"}`))
+	foreign.Header.Set("Content-Type", "application/json")
+	res = executeRequest(foreign, server)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestTagsHandlers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://tags.example.com", "alias": "tagged", "tags": ["Work"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	setTags := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/user/urls/tagged/tags", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = setTags(`["a,b"]`)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	res = setTags(`["work", "Docs"]`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"tags":["docs","work"]`)
+
+	listReq := httptest.NewRequest(http.MethodGet, "/api/user/urls?tag=docs", nil)
+	listReq.AddCookie(authCookie)
+	res = executeRequest(listReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"original_url":"https://tags.example.com"`)
+	listReq = httptest.NewRequest(http.MethodGet, "/api/user/urls?tag=missing", nil)
+	listReq.AddCookie(authCookie)
+	res = executeRequest(listReq, server)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+
+	tagsReq := httptest.NewRequest(http.MethodGet, "/api/user/tags", nil)
+	tagsReq.AddCookie(authCookie)
+	res = executeRequest(tagsReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `[{"tag":"docs","count":1},{"tag":"work","count":1}]`, res.Body.String())
+
+	foreign := httptest.NewRequest(http.MethodPut, "/api/user/urls/tagged/tags", strings.NewReader(`["x"]`))
+	foreign.Header.Set("Content-Type", "application/json")
+	res = executeRequest(foreign, server)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestTrashHandlers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://trashed.example.com", "alias": "trashed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	trashReq := httptest.NewRequest(http.MethodGet, "/api/user/urls/trash", nil)
+	trashReq.AddCookie(authCookie)
+	res = executeRequest(trashReq, server)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+
+	stored, err := repo.Get(ctx, "trashed")
+	assert.NoError(t, err)
+	repo.MarkDeletedUserURLs(ctx, storage.URLForDelete{ShortID: "trashed", UserID: stored.UserID})
+
+	trashReq = httptest.NewRequest(http.MethodGet, "/api/user/urls/trash", nil)
+	trashReq.AddCookie(authCookie)
+	res = executeRequest(trashReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"original_url":"https://trashed.example.com"`)
+	assert.Contains(t, res.Body.String(), `"deleted_at"`)
+	assert.Contains(t, res.Body.String(), `"restore_before"`)
+
+	restoreReq := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", strings.NewReader(`["trashed", "unknown"]`))
+	restoreReq.Header.Set("Content-Type", "application/json")
+	restoreReq.AddCookie(authCookie)
+	res = executeRequest(restoreReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `["trashed"]`, res.Body.String())
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/trashed", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+
+	anonymous := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", strings.NewReader(`["trashed"]`))
+	anonymous.Header.Set("Content-Type", "application/json")
+	res = executeRequest(anonymous, server)
+	assert.JSONEq(t, `[]`, res.Body.String())
+}
+
+func TestPurgeStatsHandler(t *testing.T) {
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/api/admin/purge", nil), server)
+	assert.Equal(t, http.StatusNotFound, res.Code, "admin routes must not be served without a token")
+
+	svc := service.NewURLService(generator, cfg.BaseURL, storage.NewInMemoryRepository(), service.WithPurgeRetention(time.Hour))
+	_, err := svc.Purge(ctx)
+	assert.NoError(t, err)
+	admin := NewServer(zl, svc, WithAdminToken("secret"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/purge", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	res = executeRequest(req, admin)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/purge", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	res = executeRequest(req, admin)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"retention":3600`)
+	assert.Contains(t, res.Body.String(), `"last_run_at"`)
+	assert.Contains(t, res.Body.String(), `"total_deleted":0`)
+}
+
+func TestDeleteUserURLsHandlerJob(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://deletion-job.example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(`["abc", "def"]`))
+	deleteReq.Header.Set("Content-Type", "application/json")
+	deleteReq.AddCookie(authCookie)
+	res = executeRequest(deleteReq, server)
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	location := res.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/api/user/urls/deletions/"))
+	assert.Contains(t, res.Body.String(), `"job_id":"`+strings.TrimPrefix(location, "/api/user/urls/deletions/")+`"`)
+
+	jobReq := httptest.NewRequest(http.MethodGet, location, nil)
+	jobReq.AddCookie(authCookie)
+	res = executeRequest(jobReq, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"status":"queued"`)
+	assert.Contains(t, res.Body.String(), `"urls":[{"id":"abc"},{"id":"def"}]`)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, location, nil), server)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestDeleteUserURLsHandlerBackpressure(t *testing.T) {
+	svc := service.NewURLService(generator, cfg.BaseURL, storage.NewInMemoryRepository(),
+		service.WithDeletionBuffer(1), service.WithDeletionFlushInterval(90*time.Second))
+	saturated := NewServer(zl, svc)
+	deleteURLs := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return executeRequest(req, saturated)
+	}
+
+	res := deleteURLs(`["abc", "def"]`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+	res = deleteURLs(`["abc"]`)
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	res = deleteURLs(`["def"]`)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	assert.Equal(t, "90", res.Header().Get("Retry-After"))
+}
+
 func TestShortenBatchHandler(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -143,6 +542,22 @@ func TestShortenBatchHandler(t *testing.T) {
 			reqBody:   `[]`,
 			resStatus: http.StatusCreated,
 		},
+		{
+			name:      "batch_with_alias",
+			reqBody:   `[{"correlation_id": "1", "original_url": "https://go.dev/doc", "alias": "go-docs"}, {"correlation_id": "2", "original_url": "https://go.dev/blog"}]`,
+			resStatus: http.StatusCreated,
+			resLen:    10,
+		},
+		{
+			name:      "batch_alias_taken",
+			reqBody:   `[{"correlation_id": "1", "original_url": "https://go.dev/play", "alias": "go-docs"}]`,
+			resStatus: http.StatusConflict,
+		},
+		{
+			name:      "batch_alias_reserved",
+			reqBody:   `[{"correlation_id": "1", "original_url": "https://go.dev/ref", "alias": "api"}]`,
+			resStatus: http.StatusBadRequest,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -194,7 +609,7 @@ func TestGetLinkHandlerErrorCases(t *testing.T) {
 		{
 			name:      "non_existent_link",
 			linkID:    "/nonexistent",
-			resStatus: http.StatusBadRequest,
+			resStatus: http.StatusNotFound,
 			setupLink: false,
 		},
 		{
@@ -258,3 +673,337 @@ func TestGetUserURLsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserURLsHandlerPages(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://pages.example.com/0", "alias": "pages0"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+	for _, i := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://pages.example.com/`+i+`", "alias": "pages`+i+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		assert.Equal(t, http.StatusCreated, executeRequest(req, server).Code)
+	}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/urls?"+query, nil)
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = list("sort=-short_url&limit=2")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `/pages2"`)
+	assert.Contains(t, res.Body.String(), `/pages1"`)
+	assert.Contains(t, res.Body.String(), `"created_at":`)
+	cursor := res.Header().Get("X-Next-Cursor")
+	assert.NotEmpty(t, cursor)
+
+	res = list("sort=-short_url&limit=2&cursor=" + cursor)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `/pages0"`)
+	assert.NotContains(t, res.Body.String(), `/pages1"`)
+	assert.Empty(t, res.Header().Get("X-Next-Cursor"))
+
+	res = list("domain=other.example.com")
+	assert.Equal(t, http.StatusNoContent, res.Code)
+
+	for _, bad := range []string{"limit=0", "limit=x", "sort=original_url", "created_after=yesterday", "sort=short_url&cursor=" + cursor} {
+		assert.Equal(t, http.StatusBadRequest, list(bad).Code, bad)
+	}
+}
+
+func TestSearchUserURLsHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://search.example.com/Needle", "tags": ["haystack"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	search := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/urls/search?"+query, nil)
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = search("q=needle")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"original_url":"https://search.example.com/Needle"`)
+	assert.Equal(t, http.StatusOK, search("q=HAY").Code)
+	assert.Equal(t, http.StatusNoContent, search("q=thread").Code)
+	assert.Equal(t, http.StatusBadRequest, search("q=+").Code)
+	assert.Equal(t, http.StatusBadRequest, search("q=needle&limit=-1").Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://search.example.com/talk", "alias": "quarterly", "title": "Quarterly Review", "notes": "Slides for the board"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(authCookie)
+	assert.Equal(t, http.StatusCreated, executeRequest(req, server).Code)
+	res = search("q=quarterly")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"title":"Quarterly Review","notes":"Slides for the board"`)
+	res = search("q=BOARD")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"original_url":"https://search.example.com/talk"`)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/user/urls/quarterly", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	assert.Equal(t, http.StatusOK, patch(`{"notes": ""}`).Code)
+	assert.Equal(t, http.StatusNoContent, search("q=board").Code)
+	assert.Equal(t, http.StatusBadRequest, patch(`{"title": "`+strings.Repeat("x", service.MaxTitleLength+1)+`"}`).Code)
+}
+
+func TestExportUserURLsHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://export.example.com/1", "alias": "export1", "tags": ["b", "a"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+	req = httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://export.example.com/2", "alias": "export2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(authCookie)
+	assert.Equal(t, http.StatusCreated, executeRequest(req, server).Code)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format="+format, nil)
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = export("csv")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", res.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(res.Body.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "short_url,original_url,created_at,tags,clicks", lines[0])
+	assert.Contains(t, lines[1], `/export1,https://export.example.com/1,`)
+	assert.True(t, strings.HasSuffix(lines[1], `,"a,b",0`), lines[1])
+
+	res = export("jsonl")
+	assert.Equal(t, http.StatusOK, res.Code)
+	lines = strings.Split(strings.TrimSpace(res.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"tags":["a","b"],"clicks":0`)
+	assert.Contains(t, lines[1], `"original_url":"https://export.example.com/2"`)
+
+	assert.Equal(t, http.StatusBadRequest, export("xml").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=jsonl", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.AddCookie(authCookie)
+	res = executeRequest(req, server)
+	assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(res.Body)
+	assert.NoError(t, err)
+	var body bytes.Buffer
+	_, err = body.ReadFrom(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(body.String(), "\n"))
+}
+
+func TestImportHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://import.example.com/existing", "alias": "importold"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	upload := func(filename, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("file", filename)
+		assert.NoError(t, err)
+		_, _ = fw.Write([]byte(content))
+		assert.NoError(t, mw.Close())
+		req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+
+	res = upload("bitly.csv", "Long URL,Custom Alias,Tags,Expiration\n"+
+		"https://import.example.com/1,imported1,a;b,2100-01-01\n"+
+		"https://import.example.com/existing,,,\n"+
+		"not a url,,,\n"+
+		"https://import.example.com/2,,,tomorrow\n")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"created":1,"duplicates":1,"rejected":2`)
+	assert.Contains(t, res.Body.String(), `{"line":2,"status":"created","short_url":"http://localhost:8080/imported1"}`)
+	assert.Contains(t, res.Body.String(), `{"line":3,"status":"duplicate","short_url":"http://localhost:8080/importold"}`)
+	assert.Contains(t, res.Body.String(), `{"line":5,"status":"rejected","error":"invalid expiry`)
+
+	res = upload("links.jsonl", `{"url": "https://import.example.com/3", "tags": ["x", "y"], "expires_in": 3600}`+"\n\n{broken\n")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"created":1,"duplicates":0,"rejected":1`)
+	assert.Contains(t, res.Body.String(), `{"line":3,"status":"rejected","error":"invalid JSON`)
+
+	assert.Equal(t, http.StatusBadRequest, upload("links.txt", "url\nhttps://import.example.com/4\n").Code)
+	assert.Equal(t, http.StatusBadRequest, upload("links.csv", "name,comment\nx,y\n").Code)
+
+	// The size limit applies to the decompressed upload, so a gzip bomb is cut off early.
+	uploadGzip := func(content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		zw := gzip.NewWriter(&body)
+		mw := multipart.NewWriter(zw)
+		fw, err := mw.CreateFormFile("file", "links.csv")
+		assert.NoError(t, err)
+		_, _ = fw.Write(content)
+		assert.NoError(t, mw.Close())
+		assert.NoError(t, zw.Close())
+		req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("Content-Encoding", "gzip")
+		req.AddCookie(authCookie)
+		return executeRequest(req, server)
+	}
+	res = uploadGzip([]byte("url\nhttps://import.example.com/gzip\n"))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"created":1`)
+	bomb := bytes.Repeat([]byte("url\n"), 3<<20)
+	res = uploadGzip(bomb)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
+
+func TestQRCodeHandler(t *testing.T) {
+	clicks := int64(1)
+	err := repo.Add(ctx, storage.StoredURL{ShortID: "qr-link", OriginalURL: "https://qr.example.com", ClicksLeft: &clicks})
+	assert.NoError(t, err)
+
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/qr-link/qr?size=128", nil), server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "image/png", res.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(res.Body.Bytes(), []byte("\x89PNG")))
+	etag := res.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Contains(t, res.Header().Get("Cache-Control"), "max-age=")
+
+	req := httptest.NewRequest(http.MethodGet, "/qr-link/qr?size=128", nil)
+	req.Header.Set("If-None-Match", etag)
+	res = executeRequest(req, server)
+	assert.Equal(t, http.StatusNotModified, res.Code)
+	assert.Empty(t, res.Body.String())
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/qr-link/qr?format=svg&fg=%23336699&bg=fff0&level=h&margin=2", nil), server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "image/svg+xml", res.Header().Get("Content-Type"))
+	assert.Contains(t, res.Body.String(), `fill="#336699"`)
+	assert.NotEqual(t, etag, res.Header().Get("ETag"))
+
+	// Rendering a code does not follow the link.
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/qr-link", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/qr-link/qr", nil), server)
+	assert.Equal(t, http.StatusGone, res.Code)
+
+	for _, query := range []string{"format=gif", "size=big", "size=8", "margin=-1", "level=X", "fg=blue"} {
+		res = executeRequest(httptest.NewRequest(http.MethodGet, "/qr-link/qr?"+query, nil), server)
+		assert.Equal(t, http.StatusBadRequest, res.Code, query)
+	}
+}
+
+func TestGetLinkHandlerPreview(t *testing.T) {
+	clicks := int64(1)
+	err := repo.Add(ctx, storage.StoredURL{ShortID: "preview-link", OriginalURL: "https://preview.example.com/page", ClicksLeft: &clicks})
+	assert.NoError(t, err)
+	stored, err := repo.Get(ctx, "preview-link")
+	assert.NoError(t, err)
+
+	for _, path := range []string{"/preview-link+", "/preview-link?preview=1&utm_source=mail"} {
+		res := executeRequest(httptest.NewRequest(http.MethodGet, path, nil), server)
+		assert.Equal(t, http.StatusOK, res.Code, path)
+		assert.Contains(t, res.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, res.Body.String(), "preview.example.com")
+		assert.Contains(t, res.Body.String(), "https://preview.example.com/page")
+		assert.Contains(t, res.Body.String(), `<time datetime="`+stored.CreatedAt.Format(time.RFC3339)+`">`)
+	}
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/preview-link?preview=1&utm_source=mail", nil), server)
+	assert.Contains(t, res.Body.String(), `href="/preview-link?utm_source=mail"`)
+
+	// Previews do not use up clicks.
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/preview-link", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://hidden.example.com", "alias": "hidden-preview", "password": "hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.Equal(t, http.StatusCreated, executeRequest(req, server).Code)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/hidden-preview+", nil), server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `name="password"`)
+	assert.NotContains(t, res.Body.String(), "hidden.example.com")
+	req = httptest.NewRequest(http.MethodPost, "/hidden-preview+", strings.NewReader("password=hunter2"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res = executeRequest(req, server)
+	assert.Equal(t, http.StatusSeeOther, res.Code)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/missing-link+", nil), server)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestUnknownLinkIsNotFound(t *testing.T) {
+	for _, path := range []string{"/unknown-link", "/unknown-link+", "/unknown-link?preview=1", "/unknown-link/qr"} {
+		res := executeRequest(httptest.NewRequest(http.MethodGet, path, nil), server)
+		assert.Equal(t, http.StatusNotFound, res.Code, path)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/unknown-link", strings.NewReader("password=hunter2"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.Equal(t, http.StatusNotFound, executeRequest(req, server).Code)
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "preview.html"), []byte(`custom preview of {{.Host}}`), 0o600)
+	assert.NoError(t, err)
+	assert.NoError(t, LoadTemplates(dir))
+	t.Cleanup(func() { _ = LoadTemplates("") })
+
+	err = repo.Add(ctx, storage.StoredURL{ShortID: "custom-preview", OriginalURL: "https://custom.example.com"})
+	assert.NoError(t, err)
+	res := executeRequest(httptest.NewRequest(http.MethodGet, "/custom-preview+", nil), server)
+	assert.Equal(t, "custom preview of custom.example.com", res.Body.String())
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/custom-preview?preview=0", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "blocked.html"), []byte(`{{.Broken`), 0o600))
+	assert.Error(t, LoadTemplates(dir))
+}
+
+func TestGetLinkHandlerTarget(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://shop.example.com/items/{sku}?lang=en", "alias": "shop-item", "query_policy": "passthrough"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := executeRequest(req, server)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	authCookie := res.Result().Cookies()[0]
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item/12345?utm_source=x&lang=de", nil), server)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.Code)
+	assert.Equal(t, "https://shop.example.com/items/12345?lang=en&utm_source=x", res.Header().Get("location"))
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item?sku=777", nil), server)
+	assert.Equal(t, "https://shop.example.com/items/777?lang=en", res.Header().Get("location"))
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item", nil), server)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item/1/2", nil), server)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item+/12345?utm_source=x", nil), server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), "https://shop.example.com/items/12345?lang=en&amp;utm_source=x")
+	assert.Contains(t, res.Body.String(), `href="/shop-item/12345?utm_source=x"`)
+
+	patch := httptest.NewRequest(http.MethodPatch, "/api/user/urls/shop-item", strings.NewReader(`{"query_policy": "override"}`))
+	patch.Header.Set("Content-Type", "application/json")
+	patch.AddCookie(authCookie)
+	res = executeRequest(patch, server)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"query_policy":"override"`)
+	res = executeRequest(httptest.NewRequest(http.MethodGet, "/shop-item/12345?lang=de", nil), server)
+	assert.Equal(t, "https://shop.example.com/items/12345?lang=de", res.Header().Get("location"))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "https://shop.example.com", "query_policy": "merge"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res = executeRequest(req, server)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
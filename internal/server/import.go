@@ -0,0 +1,290 @@
+package server
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/server/middleware"
+	"github.com/cmrd-a/shortener/internal/service"
+)
+
+// maxImportSize limits the size of an uploaded import file.
+const maxImportSize = 10 << 20
+
+// importField is a link field an import column is read into.
+type importField int
+
+const (
+	fieldOriginal importField = iota + 1
+	fieldAlias
+	fieldTags
+	fieldExpiresAt
+	fieldExpiresIn
+)
+
+// importColumns maps the column names of this service's export and of common exports
+// of other shorteners to link fields. Names are compared after normalizeColumn.
+var importColumns = map[string]importField{
+	"original_url":    fieldOriginal,
+	"original":        fieldOriginal,
+	"url":             fieldOriginal,
+	"long_url":        fieldOriginal,
+	"longurl":         fieldOriginal,
+	"destination":     fieldOriginal,
+	"destination_url": fieldOriginal,
+	"target":          fieldOriginal,
+	"target_url":      fieldOriginal,
+	"alias":           fieldAlias,
+	"custom_alias":    fieldAlias,
+	"slug":            fieldAlias,
+	"keyword":         fieldAlias,
+	"slashtag":        fieldAlias,
+	"back_half":       fieldAlias,
+	"tags":            fieldTags,
+	"tag":             fieldTags,
+	"labels":          fieldTags,
+	"expires_at":      fieldExpiresAt,
+	"expiry":          fieldExpiresAt,
+	"expires":         fieldExpiresAt,
+	"expiration":      fieldExpiresAt,
+	"expiration_date": fieldExpiresAt,
+	"expires_in":      fieldExpiresIn,
+}
+
+// importTimeLayouts are the accepted layouts of an expiry time; times without a zone are UTC.
+var importTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// ImportHandler returns an HTTP handler for importing links from a CSV or JSON Lines file
+// uploaded as the file field of a multipart form. The format is taken from the format field,
+// the file extension or the content type of the file. CSV files must start with a header row;
+// see importColumns for the recognized column names and JSON Lines keys.
+// It responds with the outcome of every row, see ImportResponse.
+func ImportHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userID := middleware.GetUserID(req.Context())
+		if userID == 0 {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		req.Body = http.MaxBytesReader(res, req.Body, maxImportSize)
+		file, header, err := req.FormFile("file")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(res, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		var rows []service.ImportRow
+		switch format := importFormat(req.FormValue("format"), header.Filename, header.Header.Get("Content-Type")); format {
+		case "csv":
+			rows, err = readImportCSV(file)
+		case "jsonl":
+			rows, err = readImportJSONL(file)
+		default:
+			http.Error(res, "unknown import format, want csv or jsonl", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		results, err := svc.Import(req.Context(), userID, rows)
+		if errors.Is(err, service.ErrTooManyImportRows) {
+			http.Error(res, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resJSON := ImportResponse{Rows: make([]ImportResponseItem, 0, len(results))}
+		for _, r := range results {
+			item := ImportResponseItem{Line: r.Line, Status: string(r.Status), ShortURL: r.ShortURL}
+			switch r.Status {
+			case service.ImportCreated:
+				resJSON.Created++
+			case service.ImportDuplicate:
+				resJSON.Duplicates++
+			case service.ImportRejected:
+				resJSON.Rejected++
+				item.Error = r.Err.Error()
+			}
+			resJSON.Rows = append(resJSON.Rows, item)
+		}
+		resBytes, err := resJSON.MarshalJSON()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(resBytes)
+	}
+}
+
+// importFormat picks the import format from the explicit format, the file name or its content type.
+func importFormat(format, filename, contentType string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	}
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return "csv"
+	case strings.HasPrefix(contentType, "application/jsonl"), strings.HasPrefix(contentType, "application/x-ndjson"):
+		return "jsonl"
+	}
+	return ""
+}
+
+// readImportCSV reads the rows of a CSV file with a header row.
+// It stops after service.MaxImportRows+1 rows, which the service rejects.
+func readImportCSV(r io.Reader) ([]service.ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("import file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]importField, len(header))
+	hasOriginal := false
+	for i, name := range header {
+		fields[i] = importColumns[normalizeColumn(name)]
+		hasOriginal = hasOriginal || fields[i] == fieldOriginal
+	}
+	if !hasOriginal {
+		return nil, errors.New("import file has no original URL column")
+	}
+
+	var rows []service.ImportRow
+	for len(rows) <= service.MaxImportRows {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, service.ImportRow{Line: parseErr.StartLine, Err: err})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		row := service.ImportRow{Line: line}
+		for i, value := range record {
+			if i < len(fields) && row.Err == nil {
+				row.Err = setImportField(&row, fields[i], value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readImportJSONL reads the rows of a JSON Lines file, one object per line; blank lines are skipped.
+// It stops after service.MaxImportRows+1 rows, which the service rejects.
+func readImportJSONL(r io.Reader) ([]service.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxImportSize)
+	var rows []service.ImportRow
+	for line := 1; scanner.Scan() && len(rows) <= service.MaxImportRows; line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		row := service.ImportRow{Line: line}
+		var object map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			row.Err = fmt.Errorf("invalid JSON: %w", err)
+			rows = append(rows, row)
+			continue
+		}
+		for key, value := range object {
+			if field := importColumns[normalizeColumn(key)]; field != 0 && row.Err == nil {
+				row.Err = setImportField(&row, field, jsonImportValue(value))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// normalizeColumn brings a column name or a JSON key to the form used by importColumns.
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// jsonImportValue converts a JSON value to the text form of a CSV field; arrays are joined with commas.
+func jsonImportValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			parts = append(parts, jsonImportValue(e))
+		}
+		return strings.Join(parts, ",")
+	}
+	return ""
+}
+
+// setImportField parses the value of a column into the row.
+func setImportField(row *service.ImportRow, field importField, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	switch field {
+	case fieldOriginal:
+		row.OriginalURL = value
+	case fieldAlias:
+		row.Alias = value
+	case fieldTags:
+		row.Tags = strings.FieldsFunc(value, func(c rune) bool { return c == ',' || c == ';' || c == '|' })
+	case fieldExpiresAt:
+		for _, layout := range importTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				row.ExpiresAt = t
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: cannot parse %q as a time", service.ErrInvalidExpiry, value)
+	case fieldExpiresIn:
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: expires_in must be a number of seconds", service.ErrInvalidExpiry)
+		}
+		row.ExpiresIn = time.Duration(seconds) * time.Second
+	}
+	return nil
+}
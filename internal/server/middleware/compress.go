@@ -1,39 +1,47 @@
 package middleware
 
 import (
-	"bytes"
 	"compress/gzip"
 	"io"
 	"net/http"
 	"strings"
 )
 
+// maxDecompressedSize limits the decompressed size of a gzip-encoded request body,
+// so that a small compressed body can not expand without bound. Handlers may set lower limits.
+const maxDecompressedSize = 16 << 20
+
 // DecompressRequest returns middleware that decompresses gzip-encoded request bodies.
+// The body is decompressed as the handler reads it, reading past maxDecompressedSize fails
+// with an *http.MaxBytesError.
 func DecompressRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-		var reader io.Reader
 		if req.Header.Get(`Content-Encoding`) == `gzip` {
 			gz, err := gzip.NewReader(req.Body)
 			if err != nil {
 				http.Error(res, err.Error(), http.StatusInternalServerError)
 				return
 			}
-			reader = gz
 			defer gz.Close()
-		} else {
-			reader = req.Body
-		}
-
-		body, err := io.ReadAll(reader)
-		if err != nil {
-			http.Error(res, err.Error(), http.StatusInternalServerError)
-			return
+			req.Body = http.MaxBytesReader(res, gzipBody{Reader: gz, body: req.Body}, maxDecompressedSize)
+			req.Header.Del(`Content-Encoding`)
+			req.ContentLength = -1
 		}
-		req.Body = io.NopCloser(bytes.NewReader(body))
 		next.ServeHTTP(res, req)
 	})
 }
 
+// gzipBody reads a decompressed request body and closes the original one.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close closes the original request body.
+func (b gzipBody) Close() error {
+	return b.body.Close()
+}
+
 // compressWriter wraps an http.ResponseWriter to provide gzip compression.
 type compressWriter struct {
 	w  http.ResponseWriter
@@ -66,6 +74,18 @@ func (c *compressWriter) WriteHeader(statusCode int) {
 	c.w.WriteHeader(statusCode)
 }
 
+// Flush sends the data compressed so far to the client, so that streamed responses are not held back.
+func (c *compressWriter) Flush() {
+	if c.zw.Flush() == nil {
+		_ = http.NewResponseController(c.w).Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.w
+}
+
 // Close closes the gzip writer.
 func (c *compressWriter) Close() error {
 	return c.zw.Close()
@@ -6,10 +6,18 @@ import (
 	"strings"
 )
 
+// uploadPaths lists the API endpoints that take a multipart/form-data file upload instead of JSON.
+var uploadPaths = []string{"/api/user/urls/import"}
+
 // CheckContentType returns middleware that validates Content-Type header for API endpoints.
-// It ensures that POST and DELETE requests to /api/ endpoints have application/json content type.
+// It ensures that POST and DELETE requests to /api/ endpoints have application/json content type,
+// except for file uploads to uploadPaths.
 func CheckContentType(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if slices.Contains(uploadPaths, req.URL.Path) && strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data") {
+			next.ServeHTTP(res, req)
+			return
+		}
 		if slices.Contains([]string{http.MethodPost, http.MethodDelete}, req.Method) &&
 			strings.Contains(req.RequestURI, "/api/") &&
 			req.Header.Get("Content-Type") != "application/json" {
@@ -32,6 +32,11 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // RequestResponseLogger returns middleware that logs HTTP request and response details.
 // It logs the method, URI, duration, status code, and response size for each request.
 func RequestResponseLogger(log *zap.Logger) func(http.Handler) http.Handler {
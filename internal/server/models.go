@@ -1,10 +1,34 @@
 package server
 
-// ShortenRequest represents the JSON request body for shortening a single URL.
+import "time"
+
+// LinkOptions holds the optional per-link settings shared by single and batch shorten requests.
+// ExpiresIn is a lifetime in seconds, ExpiresAt is an absolute RFC 3339 time; at most one of them may be set.
+// MaxClicks limits how many times the link can be followed, Password protects the link.
+// RedirectType is the redirect status code (301, 302, 307 or 308), zero means the server default.
+// QueryPolicy says what happens to the query of a request for the link: "ignore" (default) drops it,
+// "passthrough" adds the parameters the original URL does not have and "override" replaces them.
+// Title and Notes are free-form text of the owner, found by the search together with the URL and tags.
+// Tags are free-form labels; they are stored trimmed, lowercase, unique and sorted.
 //
 //go:generate easyjson -all models.go
+type LinkOptions struct {
+	Alias        string     `json:"alias,omitempty"`
+	ExpiresIn    int64      `json:"expires_in,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	MaxClicks    int64      `json:"max_clicks,omitempty"`
+	Password     string     `json:"password,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	QueryPolicy  string     `json:"query_policy,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+}
+
+// ShortenRequest represents the JSON request body for shortening a single URL.
 type ShortenRequest struct {
 	URL string `json:"url"`
+	LinkOptions
 }
 
 // ShortenResponse represents the JSON response body containing a shortened URL.
@@ -21,6 +45,7 @@ type ShortenBatchRequest []ShortenBatchRequestItem
 type ShortenBatchRequestItem struct {
 	CorrelationID string `json:"correlation_id"`
 	OriginalURL   string `json:"original_url"`
+	LinkOptions
 }
 
 // ShortenBatchResponse represents a batch response containing multiple shortened URLs.
@@ -41,11 +66,169 @@ type GetUserURLsResponse []GetUserURLsResponseItem
 
 // GetUserURLsResponseItem represents a single URL item in the user's URL list.
 type GetUserURLsResponseItem struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	ShortURL     string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ClicksLeft   *int64     `json:"clicks_left,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	QueryPolicy  string     `json:"query_policy,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// ExportItem represents a single URL in a JSON Lines export.
+// CreatedAt is omitted when the creation time of the URL is unknown.
+type ExportItem struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Tags        []string   `json:"tags"`
+	Clicks      int64      `json:"clicks"`
+}
+
+// ImportResponse represents the report of an import with the outcome of every row, in the order of the rows.
+type ImportResponse struct {
+	Created    int                  `json:"created"`
+	Duplicates int                  `json:"duplicates"`
+	Rejected   int                  `json:"rejected"`
+	Rows       []ImportResponseItem `json:"rows"`
+}
+
+// ImportResponseItem represents the outcome of importing a row: "created", "duplicate" or "rejected".
+// ShortURL is the created link or, for a duplicate, the existing link of the same original URL;
+// Error explains why the row was rejected.
+type ImportResponseItem struct {
+	Line     int    `json:"line"`
+	Status   string `json:"status"`
+	ShortURL string `json:"short_url,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SetTagsRequest represents the new tags of a link, replacing the old ones; an empty list removes all tags.
+//
+//easyjson:json
+type SetTagsRequest []string
+
+// TagsResponse represents the tags of a user's links, most used first.
+//
+//easyjson:json
+type TagsResponse []TagsResponseItem
+
+// TagsResponseItem represents a tag with the number of links that carry it.
+type TagsResponseItem struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// UpdateURLRequest represents a change of an existing link, omitted fields are left as is.
+// ExpiresIn (seconds) or ExpiresAt set a new expiry and NoExpiry removes it.
+// A zero RedirectType resets the link to the server default, an empty QueryPolicy resets it to "ignore"
+// and an empty Title or Notes clears it.
+type UpdateURLRequest struct {
+	OriginalURL  *string    `json:"original_url,omitempty"`
+	RedirectType *int       `json:"redirect_type,omitempty"`
+	QueryPolicy  *string    `json:"query_policy,omitempty"`
+	Title        *string    `json:"title,omitempty"`
+	Notes        *string    `json:"notes,omitempty"`
+	ExpiresIn    int64      `json:"expires_in,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	NoExpiry     bool       `json:"no_expiry,omitempty"`
+}
+
+// RollbackRequest represents a request to restore a link from one of its revisions.
+type RollbackRequest struct {
+	Revision int64 `json:"revision"`
+}
+
+// HistoryResponse represents the revisions of a link, oldest first.
+//
+//easyjson:json
+type HistoryResponse []HistoryResponseItem
+
+// HistoryResponseItem represents a single revision of a link.
+// CreatedAt is omitted when the creation time of the link is unknown.
+type HistoryResponseItem struct {
+	Revision     int64      `json:"revision"`
+	OriginalURL  string     `json:"original_url"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	QueryPolicy  string     `json:"query_policy,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	AuthorID     int64      `json:"author_id"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
 }
 
 // DeleteUserURLsRequest represents a request to delete multiple URLs for a user.
 //
 //easyjson:json
 type DeleteUserURLsRequest []string
+
+// DeleteUserURLsResponse holds the ID of the job deleting the URLs.
+type DeleteUserURLsResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// DeletionJobResponse represents the state of a deletion job:
+// "queued", "applied", "partially_applied" or "failed".
+// AppliedAt is omitted while the job is queued.
+type DeletionJobResponse struct {
+	JobID     string                    `json:"job_id"`
+	Status    string                    `json:"status"`
+	CreatedAt time.Time                 `json:"created_at"`
+	AppliedAt *time.Time                `json:"applied_at,omitempty"`
+	URLs      []DeletionJobResponseItem `json:"urls"`
+}
+
+// DeletionJobResponseItem represents the outcome of deleting a single short ID:
+// "deleted", "already_deleted", "not_found" or "not_owned". It is omitted while the job is queued.
+type DeletionJobResponseItem struct {
+	ID      string `json:"id"`
+	Outcome string `json:"outcome,omitempty"`
+}
+
+// TrashResponse represents the deleted URLs of a user, most recently deleted first.
+//
+//easyjson:json
+type TrashResponse []TrashResponseItem
+
+// TrashResponseItem represents a single deleted URL.
+// RestoreBefore is omitted when the URL can no longer be restored.
+type TrashResponseItem struct {
+	ShortURL      string     `json:"short_url"`
+	OriginalURL   string     `json:"original_url"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	RestoreBefore *time.Time `json:"restore_before,omitempty"`
+}
+
+// RestoreUserURLsRequest represents a request to restore deleted URLs of a user.
+//
+//easyjson:json
+type RestoreUserURLsRequest []string
+
+// RestoreUserURLsResponse lists the short IDs of the restored URLs.
+//
+//easyjson:json
+type RestoreUserURLsResponse []string
+
+// PurgeStatsResponse represents the hard purge statistics.
+// Retention is in seconds, zero if purging is disabled; LastRunAt is omitted until the first purge.
+type PurgeStatsResponse struct {
+	Retention    int64      `json:"retention"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDeleted  int64      `json:"last_deleted"`
+	LastExpired  int64      `json:"last_expired"`
+	TotalDeleted int64      `json:"total_deleted"`
+	TotalExpired int64      `json:"total_expired"`
+}
+
+// ClickStatsResponse represents the aggregated clicks of a short link.
+// ByDay is keyed by UTC date, ByReferrer by referring host ("direct" when there is none).
+type ClickStatsResponse struct {
+	Total      int64            `json:"total"`
+	ByDay      map[string]int64 `json:"by_day"`
+	ByReferrer map[string]int64 `json:"by_referrer"`
+	ByBrowser  map[string]int64 `json:"by_browser"`
+}
@@ -4,6 +4,7 @@ package server
 
 import (
 	json "encoding/json"
+	time "time"
 
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
@@ -18,7 +19,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(in *jlexer.Lexer, out *ShortenResponse) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(in *jlexer.Lexer, out *UpdateURLRequest) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -37,8 +38,72 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(in *jlexer.Lexe
 			continue
 		}
 		switch key {
-		case "result":
-			out.Result = string(in.String())
+		case "original_url":
+			if in.IsNull() {
+				in.Skip()
+				out.OriginalURL = nil
+			} else {
+				if out.OriginalURL == nil {
+					out.OriginalURL = new(string)
+				}
+				*out.OriginalURL = string(in.String())
+			}
+		case "redirect_type":
+			if in.IsNull() {
+				in.Skip()
+				out.RedirectType = nil
+			} else {
+				if out.RedirectType == nil {
+					out.RedirectType = new(int)
+				}
+				*out.RedirectType = int(in.Int())
+			}
+		case "query_policy":
+			if in.IsNull() {
+				in.Skip()
+				out.QueryPolicy = nil
+			} else {
+				if out.QueryPolicy == nil {
+					out.QueryPolicy = new(string)
+				}
+				*out.QueryPolicy = string(in.String())
+			}
+		case "title":
+			if in.IsNull() {
+				in.Skip()
+				out.Title = nil
+			} else {
+				if out.Title == nil {
+					out.Title = new(string)
+				}
+				*out.Title = string(in.String())
+			}
+		case "notes":
+			if in.IsNull() {
+				in.Skip()
+				out.Notes = nil
+			} else {
+				if out.Notes == nil {
+					out.Notes = new(string)
+				}
+				*out.Notes = string(in.String())
+			}
+		case "expires_in":
+			out.ExpiresIn = int64(in.Int64())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "no_expiry":
+			out.NoExpiry = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -49,108 +114,113 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(in *jlexer.Lexe
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(out *jwriter.Writer, in ShortenResponse) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(out *jwriter.Writer, in UpdateURLRequest) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"result\":"
+	if in.OriginalURL != nil {
+		const prefix string = ",\"original_url\":"
+		first = false
 		out.RawString(prefix[1:])
-		out.String(string(in.Result))
+		out.String(string(*in.OriginalURL))
 	}
-	out.RawByte('}')
-}
-
-// MarshalJSON supports json.Marshaler interface
-func (v ShortenResponse) MarshalJSON() ([]byte, error) {
-	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(&w, v)
-	return w.Buffer.BuildBytes(), w.Error
-}
-
-// MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(w, v)
-}
-
-// UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenResponse) UnmarshalJSON(data []byte) error {
-	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(&r, v)
-	return r.Error()
-}
-
-// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(l, v)
-}
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(in *jlexer.Lexer, out *ShortenRequest) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
+	if in.RedirectType != nil {
+		const prefix string = ",\"redirect_type\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
 		}
-		in.Skip()
-		return
+		out.Int(int(*in.RedirectType))
 	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeFieldName(false)
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
+	if in.QueryPolicy != nil {
+		const prefix string = ",\"query_policy\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
 		}
-		switch key {
-		case "url":
-			out.URL = string(in.String())
-		default:
-			in.SkipRecursive()
+		out.String(string(*in.QueryPolicy))
+	}
+	if in.Title != nil {
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
 		}
-		in.WantComma()
+		out.String(string(*in.Title))
 	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
+	if in.Notes != nil {
+		const prefix string = ",\"notes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Notes))
 	}
-}
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(out *jwriter.Writer, in ShortenRequest) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	{
-		const prefix string = ",\"url\":"
-		out.RawString(prefix[1:])
-		out.String(string(in.URL))
+	if in.ExpiresIn != 0 {
+		const prefix string = ",\"expires_in\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.ExpiresIn))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.NoExpiry {
+		const prefix string = ",\"no_expiry\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.NoExpiry))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortenRequest) MarshalJSON() ([]byte, error) {
+func (v UpdateURLRequest) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenRequest) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(w, v)
+func (v UpdateURLRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenRequest) UnmarshalJSON(data []byte) error {
+func (v *UpdateURLRequest) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(l, v)
+func (v *UpdateURLRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(in *jlexer.Lexer, out *ShortenBatchResponseItem) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(in *jlexer.Lexer, out *TrashResponseItem) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -169,10 +239,34 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(in *jlexer.Lex
 			continue
 		}
 		switch key {
-		case "correlation_id":
-			out.CorrelationID = string(in.String())
 		case "short_url":
 			out.ShortURL = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "restore_before":
+			if in.IsNull() {
+				in.Skip()
+				out.RestoreBefore = nil
+			} else {
+				if out.RestoreBefore == nil {
+					out.RestoreBefore = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.RestoreBefore).UnmarshalJSON(data))
+				}
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -183,47 +277,57 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(out *jwriter.Writer, in ShortenBatchResponseItem) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(out *jwriter.Writer, in TrashResponseItem) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"correlation_id\":"
+		const prefix string = ",\"short_url\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.CorrelationID))
+		out.String(string(in.ShortURL))
 	}
 	{
-		const prefix string = ",\"short_url\":"
+		const prefix string = ",\"original_url\":"
 		out.RawString(prefix)
-		out.String(string(in.ShortURL))
+		out.String(string(in.OriginalURL))
+	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	if in.RestoreBefore != nil {
+		const prefix string = ",\"restore_before\":"
+		out.RawString(prefix)
+		out.Raw((*in.RestoreBefore).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortenBatchResponseItem) MarshalJSON() ([]byte, error) {
+func (v TrashResponseItem) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenBatchResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(w, v)
+func (v TrashResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenBatchResponseItem) UnmarshalJSON(data []byte) error {
+func (v *TrashResponseItem) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenBatchResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(l, v)
+func (v *TrashResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer1(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(in *jlexer.Lexer, out *ShortenBatchResponse) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(in *jlexer.Lexer, out *TrashResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -232,15 +336,15 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(in *jlexer.Lex
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(ShortenBatchResponse, 0, 2)
+				*out = make(TrashResponse, 0, 1)
 			} else {
-				*out = ShortenBatchResponse{}
+				*out = TrashResponse{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v1 ShortenBatchResponseItem
+			var v1 TrashResponseItem
 			(v1).UnmarshalEasyJSON(in)
 			*out = append(*out, v1)
 			in.WantComma()
@@ -251,7 +355,7 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(out *jwriter.Writer, in ShortenBatchResponse) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(out *jwriter.Writer, in TrashResponse) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -267,29 +371,29 @@ func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(out *jwriter.W
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortenBatchResponse) MarshalJSON() ([]byte, error) {
+func (v TrashResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenBatchResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(w, v)
+func (v TrashResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenBatchResponse) UnmarshalJSON(data []byte) error {
+func (v *TrashResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenBatchResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(l, v)
+func (v *TrashResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer2(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(in *jlexer.Lexer, out *ShortenBatchRequestItem) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(in *jlexer.Lexer, out *TagsResponseItem) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -308,10 +412,10 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(in *jlexer.Lex
 			continue
 		}
 		switch key {
-		case "correlation_id":
-			out.CorrelationID = string(in.String())
-		case "original_url":
-			out.OriginalURL = string(in.String())
+		case "tag":
+			out.Tag = string(in.String())
+		case "count":
+			out.Count = int64(in.Int64())
 		default:
 			in.SkipRecursive()
 		}
@@ -322,47 +426,47 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(out *jwriter.Writer, in ShortenBatchRequestItem) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(out *jwriter.Writer, in TagsResponseItem) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"correlation_id\":"
+		const prefix string = ",\"tag\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.CorrelationID))
+		out.String(string(in.Tag))
 	}
 	{
-		const prefix string = ",\"original_url\":"
+		const prefix string = ",\"count\":"
 		out.RawString(prefix)
-		out.String(string(in.OriginalURL))
+		out.Int64(int64(in.Count))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortenBatchRequestItem) MarshalJSON() ([]byte, error) {
+func (v TagsResponseItem) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenBatchRequestItem) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(w, v)
+func (v TagsResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenBatchRequestItem) UnmarshalJSON(data []byte) error {
+func (v *TagsResponseItem) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenBatchRequestItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(l, v)
+func (v *TagsResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer3(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(in *jlexer.Lexer, out *ShortenBatchRequest) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(in *jlexer.Lexer, out *TagsResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -371,15 +475,15 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(in *jlexer.Lex
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(ShortenBatchRequest, 0, 2)
+				*out = make(TagsResponse, 0, 2)
 			} else {
-				*out = ShortenBatchRequest{}
+				*out = TagsResponse{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v4 ShortenBatchRequestItem
+			var v4 TagsResponseItem
 			(v4).UnmarshalEasyJSON(in)
 			*out = append(*out, v4)
 			in.WantComma()
@@ -390,7 +494,7 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(out *jwriter.Writer, in ShortenBatchRequest) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(out *jwriter.Writer, in TagsResponse) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -406,29 +510,29 @@ func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(out *jwriter.W
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortenBatchRequest) MarshalJSON() ([]byte, error) {
+func (v TagsResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortenBatchRequest) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(w, v)
+func (v TagsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortenBatchRequest) UnmarshalJSON(data []byte) error {
+func (v *TagsResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortenBatchRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(l, v)
+func (v *TagsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer4(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(in *jlexer.Lexer, out *GetUserURLsResponseItem) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(in *jlexer.Lexer, out *ShortenResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -447,10 +551,8 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(in *jlexer.Lex
 			continue
 		}
 		switch key {
-		case "short_url":
-			out.ShortURL = string(in.String())
-		case "original_url":
-			out.OriginalURL = string(in.String())
+		case "result":
+			out.Result = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -461,113 +563,291 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(out *jwriter.Writer, in GetUserURLsResponseItem) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(out *jwriter.Writer, in ShortenResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"short_url\":"
+		const prefix string = ",\"result\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.ShortURL))
-	}
-	{
-		const prefix string = ",\"original_url\":"
-		out.RawString(prefix)
-		out.String(string(in.OriginalURL))
+		out.String(string(in.Result))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v GetUserURLsResponseItem) MarshalJSON() ([]byte, error) {
+func (v ShortenResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v GetUserURLsResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(w, v)
+func (v ShortenResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *GetUserURLsResponseItem) UnmarshalJSON(data []byte) error {
+func (v *ShortenResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *GetUserURLsResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(l, v)
+func (v *ShortenResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer5(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer7(in *jlexer.Lexer, out *GetUserURLsResponse) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(in *jlexer.Lexer, out *ShortenRequest) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
 		in.Skip()
-		*out = nil
-	} else {
-		in.Delim('[')
-		if *out == nil {
-			if !in.IsDelim(']') {
-				*out = make(GetUserURLsResponse, 0, 2)
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "url":
+			out.URL = string(in.String())
+		case "alias":
+			out.Alias = string(in.String())
+		case "expires_in":
+			out.ExpiresIn = int64(in.Int64())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
 			} else {
-				*out = GetUserURLsResponse{}
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
 			}
-		} else {
-			*out = (*out)[:0]
-		}
-		for !in.IsDelim(']') {
-			var v7 GetUserURLsResponseItem
-			(v7).UnmarshalEasyJSON(in)
-			*out = append(*out, v7)
-			in.WantComma()
+		case "max_clicks":
+			out.MaxClicks = int64(in.Int64())
+		case "password":
+			out.Password = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 string
+					v7 = string(in.String())
+					out.Tags = append(out.Tags, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
 		}
-		in.Delim(']')
+		in.WantComma()
 	}
+	in.Delim('}')
 	if isTopLevel {
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer7(out *jwriter.Writer, in GetUserURLsResponse) {
-	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
-		out.RawString("null")
-	} else {
-		out.RawByte('[')
-		for v8, v9 := range in {
-			if v8 > 0 {
-				out.RawByte(',')
-			}
-			(v9).MarshalEasyJSON(out)
-		}
-		out.RawByte(']')
-	}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(out *jwriter.Writer, in ShortenRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.URL))
+	}
+	if in.Alias != "" {
+		const prefix string = ",\"alias\":"
+		out.RawString(prefix)
+		out.String(string(in.Alias))
+	}
+	if in.ExpiresIn != 0 {
+		const prefix string = ",\"expires_in\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ExpiresIn))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.MaxClicks != 0 {
+		const prefix string = ",\"max_clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.MaxClicks))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.Tags {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v GetUserURLsResponse) MarshalJSON() ([]byte, error) {
+func (v ShortenRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ShortenRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ShortenRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ShortenRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer6(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer7(in *jlexer.Lexer, out *ShortenBatchResponseItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlation_id":
+			out.CorrelationID = string(in.String())
+		case "short_url":
+			out.ShortURL = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer7(out *jwriter.Writer, in ShortenBatchResponseItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlation_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationID))
+	}
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortURL))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ShortenBatchResponseItem) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v GetUserURLsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+func (v ShortenBatchResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *GetUserURLsResponse) UnmarshalJSON(data []byte) error {
+func (v *ShortenBatchResponseItem) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *GetUserURLsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *ShortenBatchResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer7(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(in *jlexer.Lexer, out *DeleteUserURLsRequest) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(in *jlexer.Lexer, out *ShortenBatchResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -576,16 +856,16 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(in *jlexer.Lex
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(DeleteUserURLsRequest, 0, 4)
+				*out = make(ShortenBatchResponse, 0, 2)
 			} else {
-				*out = DeleteUserURLsRequest{}
+				*out = ShortenBatchResponse{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v10 string
-			v10 = string(in.String())
+			var v10 ShortenBatchResponseItem
+			(v10).UnmarshalEasyJSON(in)
 			*out = append(*out, v10)
 			in.WantComma()
 		}
@@ -595,7 +875,7 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer8(out *jwriter.Writer, in DeleteUserURLsRequest) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer8(out *jwriter.Writer, in ShortenBatchResponse) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -604,32 +884,2194 @@ func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer8(out *jwriter.W
 			if v11 > 0 {
 				out.RawByte(',')
 			}
-			out.String(string(v12))
+			(v12).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v DeleteUserURLsRequest) MarshalJSON() ([]byte, error) {
+func (v ShortenBatchResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v DeleteUserURLsRequest) MarshalEasyJSON(w *jwriter.Writer) {
+func (v ShortenBatchResponse) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *DeleteUserURLsRequest) UnmarshalJSON(data []byte) error {
+func (v *ShortenBatchResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *DeleteUserURLsRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *ShortenBatchResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer8(l, v)
 }
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer9(in *jlexer.Lexer, out *ShortenBatchRequestItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlation_id":
+			out.CorrelationID = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "alias":
+			out.Alias = string(in.String())
+		case "expires_in":
+			out.ExpiresIn = int64(in.Int64())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "max_clicks":
+			out.MaxClicks = int64(in.Int64())
+		case "password":
+			out.Password = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 string
+					v13 = string(in.String())
+					out.Tags = append(out.Tags, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer9(out *jwriter.Writer, in ShortenBatchRequestItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlation_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationID))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.Alias != "" {
+		const prefix string = ",\"alias\":"
+		out.RawString(prefix)
+		out.String(string(in.Alias))
+	}
+	if in.ExpiresIn != 0 {
+		const prefix string = ",\"expires_in\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ExpiresIn))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.MaxClicks != 0 {
+		const prefix string = ",\"max_clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.MaxClicks))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v14, v15 := range in.Tags {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v15))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ShortenBatchRequestItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ShortenBatchRequestItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ShortenBatchRequestItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ShortenBatchRequestItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer9(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer10(in *jlexer.Lexer, out *ShortenBatchRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ShortenBatchRequest, 0, 0)
+			} else {
+				*out = ShortenBatchRequest{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v16 ShortenBatchRequestItem
+			(v16).UnmarshalEasyJSON(in)
+			*out = append(*out, v16)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer10(out *jwriter.Writer, in ShortenBatchRequest) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v17, v18 := range in {
+			if v17 > 0 {
+				out.RawByte(',')
+			}
+			(v18).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ShortenBatchRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ShortenBatchRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ShortenBatchRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ShortenBatchRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer10(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer11(in *jlexer.Lexer, out *SetTagsRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SetTagsRequest, 0, 4)
+			} else {
+				*out = SetTagsRequest{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v19 string
+			v19 = string(in.String())
+			*out = append(*out, v19)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer11(out *jwriter.Writer, in SetTagsRequest) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v20, v21 := range in {
+			if v20 > 0 {
+				out.RawByte(',')
+			}
+			out.String(string(v21))
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SetTagsRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SetTagsRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SetTagsRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SetTagsRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer11(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer12(in *jlexer.Lexer, out *RollbackRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "revision":
+			out.Revision = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer12(out *jwriter.Writer, in RollbackRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Revision))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RollbackRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RollbackRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RollbackRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RollbackRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer12(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer13(in *jlexer.Lexer, out *RestoreUserURLsResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(RestoreUserURLsResponse, 0, 4)
+			} else {
+				*out = RestoreUserURLsResponse{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v22 string
+			v22 = string(in.String())
+			*out = append(*out, v22)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer13(out *jwriter.Writer, in RestoreUserURLsResponse) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v23, v24 := range in {
+			if v23 > 0 {
+				out.RawByte(',')
+			}
+			out.String(string(v24))
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RestoreUserURLsResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RestoreUserURLsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RestoreUserURLsResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RestoreUserURLsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer13(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer14(in *jlexer.Lexer, out *RestoreUserURLsRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(RestoreUserURLsRequest, 0, 4)
+			} else {
+				*out = RestoreUserURLsRequest{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v25 string
+			v25 = string(in.String())
+			*out = append(*out, v25)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer14(out *jwriter.Writer, in RestoreUserURLsRequest) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v26, v27 := range in {
+			if v26 > 0 {
+				out.RawByte(',')
+			}
+			out.String(string(v27))
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RestoreUserURLsRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RestoreUserURLsRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RestoreUserURLsRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RestoreUserURLsRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer14(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer15(in *jlexer.Lexer, out *PurgeStatsResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "retention":
+			out.Retention = int64(in.Int64())
+		case "last_run_at":
+			if in.IsNull() {
+				in.Skip()
+				out.LastRunAt = nil
+			} else {
+				if out.LastRunAt == nil {
+					out.LastRunAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastRunAt).UnmarshalJSON(data))
+				}
+			}
+		case "last_deleted":
+			out.LastDeleted = int64(in.Int64())
+		case "last_expired":
+			out.LastExpired = int64(in.Int64())
+		case "total_deleted":
+			out.TotalDeleted = int64(in.Int64())
+		case "total_expired":
+			out.TotalExpired = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer15(out *jwriter.Writer, in PurgeStatsResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"retention\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Retention))
+	}
+	if in.LastRunAt != nil {
+		const prefix string = ",\"last_run_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastRunAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"last_deleted\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastDeleted))
+	}
+	{
+		const prefix string = ",\"last_expired\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastExpired))
+	}
+	{
+		const prefix string = ",\"total_deleted\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TotalDeleted))
+	}
+	{
+		const prefix string = ",\"total_expired\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TotalExpired))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PurgeStatsResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PurgeStatsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PurgeStatsResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PurgeStatsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer15(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer16(in *jlexer.Lexer, out *LinkOptions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "alias":
+			out.Alias = string(in.String())
+		case "expires_in":
+			out.ExpiresIn = int64(in.Int64())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "max_clicks":
+			out.MaxClicks = int64(in.Int64())
+		case "password":
+			out.Password = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v28 string
+					v28 = string(in.String())
+					out.Tags = append(out.Tags, v28)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer16(out *jwriter.Writer, in LinkOptions) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Alias != "" {
+		const prefix string = ",\"alias\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Alias))
+	}
+	if in.ExpiresIn != 0 {
+		const prefix string = ",\"expires_in\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.ExpiresIn))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.MaxClicks != 0 {
+		const prefix string = ",\"max_clicks\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.MaxClicks))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Password))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Notes))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v29, v30 := range in.Tags {
+				if v29 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v30))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LinkOptions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LinkOptions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LinkOptions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LinkOptions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer16(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer17(in *jlexer.Lexer, out *ImportResponseItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "line":
+			out.Line = int(in.Int())
+		case "status":
+			out.Status = string(in.String())
+		case "short_url":
+			out.ShortURL = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer17(out *jwriter.Writer, in ImportResponseItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"line\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Line))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.ShortURL != "" {
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortURL))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportResponseItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportResponseItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer17(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer18(in *jlexer.Lexer, out *ImportResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "created":
+			out.Created = int(in.Int())
+		case "duplicates":
+			out.Duplicates = int(in.Int())
+		case "rejected":
+			out.Rejected = int(in.Int())
+		case "rows":
+			if in.IsNull() {
+				in.Skip()
+				out.Rows = nil
+			} else {
+				in.Delim('[')
+				if out.Rows == nil {
+					if !in.IsDelim(']') {
+						out.Rows = make([]ImportResponseItem, 0, 1)
+					} else {
+						out.Rows = []ImportResponseItem{}
+					}
+				} else {
+					out.Rows = (out.Rows)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v31 ImportResponseItem
+					(v31).UnmarshalEasyJSON(in)
+					out.Rows = append(out.Rows, v31)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer18(out *jwriter.Writer, in ImportResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Created))
+	}
+	{
+		const prefix string = ",\"duplicates\":"
+		out.RawString(prefix)
+		out.Int(int(in.Duplicates))
+	}
+	{
+		const prefix string = ",\"rejected\":"
+		out.RawString(prefix)
+		out.Int(int(in.Rejected))
+	}
+	{
+		const prefix string = ",\"rows\":"
+		out.RawString(prefix)
+		if in.Rows == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v32, v33 := range in.Rows {
+				if v32 > 0 {
+					out.RawByte(',')
+				}
+				(v33).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer18(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer19(in *jlexer.Lexer, out *HistoryResponseItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "revision":
+			out.Revision = int64(in.Int64())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "author_id":
+			out.AuthorID = int64(in.Int64())
+		case "created_at":
+			if in.IsNull() {
+				in.Skip()
+				out.CreatedAt = nil
+			} else {
+				if out.CreatedAt == nil {
+					out.CreatedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.CreatedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer19(out *jwriter.Writer, in HistoryResponseItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Revision))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	{
+		const prefix string = ",\"author_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.AuthorID))
+	}
+	if in.CreatedAt != nil {
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistoryResponseItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistoryResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistoryResponseItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistoryResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer19(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer20(in *jlexer.Lexer, out *HistoryResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(HistoryResponse, 0, 0)
+			} else {
+				*out = HistoryResponse{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v34 HistoryResponseItem
+			(v34).UnmarshalEasyJSON(in)
+			*out = append(*out, v34)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer20(out *jwriter.Writer, in HistoryResponse) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v35, v36 := range in {
+			if v35 > 0 {
+				out.RawByte(',')
+			}
+			(v36).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistoryResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistoryResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistoryResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistoryResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer20(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer21(in *jlexer.Lexer, out *GetUserURLsResponseItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "short_url":
+			out.ShortURL = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "clicks_left":
+			if in.IsNull() {
+				in.Skip()
+				out.ClicksLeft = nil
+			} else {
+				if out.ClicksLeft == nil {
+					out.ClicksLeft = new(int64)
+				}
+				*out.ClicksLeft = int64(in.Int64())
+			}
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v37 string
+					v37 = string(in.String())
+					out.Tags = append(out.Tags, v37)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "created_at":
+			if in.IsNull() {
+				in.Skip()
+				out.CreatedAt = nil
+			} else {
+				if out.CreatedAt == nil {
+					out.CreatedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.CreatedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer21(out *jwriter.Writer, in GetUserURLsResponseItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ShortURL))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.ClicksLeft != nil {
+		const prefix string = ",\"clicks_left\":"
+		out.RawString(prefix)
+		out.Int64(int64(*in.ClicksLeft))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v38, v39 := range in.Tags {
+				if v38 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v39))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.CreatedAt != nil {
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GetUserURLsResponseItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer21(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GetUserURLsResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer21(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GetUserURLsResponseItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer21(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GetUserURLsResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer21(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer22(in *jlexer.Lexer, out *GetUserURLsResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(GetUserURLsResponse, 0, 0)
+			} else {
+				*out = GetUserURLsResponse{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v40 GetUserURLsResponseItem
+			(v40).UnmarshalEasyJSON(in)
+			*out = append(*out, v40)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer22(out *jwriter.Writer, in GetUserURLsResponse) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v41, v42 := range in {
+			if v41 > 0 {
+				out.RawByte(',')
+			}
+			(v42).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GetUserURLsResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer22(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GetUserURLsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer22(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GetUserURLsResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer22(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GetUserURLsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer22(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer23(in *jlexer.Lexer, out *ExportItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "short_url":
+			out.ShortURL = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "created_at":
+			if in.IsNull() {
+				in.Skip()
+				out.CreatedAt = nil
+			} else {
+				if out.CreatedAt == nil {
+					out.CreatedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.CreatedAt).UnmarshalJSON(data))
+				}
+			}
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v43 string
+					v43 = string(in.String())
+					out.Tags = append(out.Tags, v43)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "clicks":
+			out.Clicks = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer23(out *jwriter.Writer, in ExportItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ShortURL))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.CreatedAt != nil {
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.CreatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		if in.Tags == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v44, v45 := range in.Tags {
+				if v44 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v45))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Clicks))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ExportItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer23(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ExportItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer23(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ExportItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer23(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ExportItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer23(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer24(in *jlexer.Lexer, out *DeletionJobResponseItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "outcome":
+			out.Outcome = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer24(out *jwriter.Writer, in DeletionJobResponseItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	if in.Outcome != "" {
+		const prefix string = ",\"outcome\":"
+		out.RawString(prefix)
+		out.String(string(in.Outcome))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeletionJobResponseItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer24(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeletionJobResponseItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer24(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeletionJobResponseItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer24(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeletionJobResponseItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer24(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer25(in *jlexer.Lexer, out *DeletionJobResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "job_id":
+			out.JobID = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "applied_at":
+			if in.IsNull() {
+				in.Skip()
+				out.AppliedAt = nil
+			} else {
+				if out.AppliedAt == nil {
+					out.AppliedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.AppliedAt).UnmarshalJSON(data))
+				}
+			}
+		case "urls":
+			if in.IsNull() {
+				in.Skip()
+				out.URLs = nil
+			} else {
+				in.Delim('[')
+				if out.URLs == nil {
+					if !in.IsDelim(']') {
+						out.URLs = make([]DeletionJobResponseItem, 0, 2)
+					} else {
+						out.URLs = []DeletionJobResponseItem{}
+					}
+				} else {
+					out.URLs = (out.URLs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v46 DeletionJobResponseItem
+					(v46).UnmarshalEasyJSON(in)
+					out.URLs = append(out.URLs, v46)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer25(out *jwriter.Writer, in DeletionJobResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"job_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.JobID))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	if in.AppliedAt != nil {
+		const prefix string = ",\"applied_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.AppliedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"urls\":"
+		out.RawString(prefix)
+		if in.URLs == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v47, v48 := range in.URLs {
+				if v47 > 0 {
+					out.RawByte(',')
+				}
+				(v48).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeletionJobResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer25(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeletionJobResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer25(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeletionJobResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer25(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeletionJobResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer25(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer26(in *jlexer.Lexer, out *DeleteUserURLsResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "job_id":
+			out.JobID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer26(out *jwriter.Writer, in DeleteUserURLsResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"job_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.JobID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeleteUserURLsResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer26(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeleteUserURLsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer26(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeleteUserURLsResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer26(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeleteUserURLsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer26(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer27(in *jlexer.Lexer, out *DeleteUserURLsRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(DeleteUserURLsRequest, 0, 4)
+			} else {
+				*out = DeleteUserURLsRequest{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v49 string
+			v49 = string(in.String())
+			*out = append(*out, v49)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer27(out *jwriter.Writer, in DeleteUserURLsRequest) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v50, v51 := range in {
+			if v50 > 0 {
+				out.RawByte(',')
+			}
+			out.String(string(v51))
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeleteUserURLsRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer27(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeleteUserURLsRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer27(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeleteUserURLsRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer27(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeleteUserURLsRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer27(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer28(in *jlexer.Lexer, out *ClickStatsResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "total":
+			out.Total = int64(in.Int64())
+		case "by_day":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByDay = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v52 int64
+					v52 = int64(in.Int64())
+					(out.ByDay)[key] = v52
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "by_referrer":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByReferrer = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v53 int64
+					v53 = int64(in.Int64())
+					(out.ByReferrer)[key] = v53
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "by_browser":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByBrowser = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v54 int64
+					v54 = int64(in.Int64())
+					(out.ByBrowser)[key] = v54
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer28(out *jwriter.Writer, in ClickStatsResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Total))
+	}
+	{
+		const prefix string = ",\"by_day\":"
+		out.RawString(prefix)
+		if in.ByDay == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v55First := true
+			for v55Name, v55Value := range in.ByDay {
+				if v55First {
+					v55First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v55Name))
+				out.RawByte(':')
+				out.Int64(int64(v55Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"by_referrer\":"
+		out.RawString(prefix)
+		if in.ByReferrer == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v56First := true
+			for v56Name, v56Value := range in.ByReferrer {
+				if v56First {
+					v56First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v56Name))
+				out.RawByte(':')
+				out.Int64(int64(v56Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"by_browser\":"
+		out.RawString(prefix)
+		if in.ByBrowser == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v57First := true
+			for v57Name, v57Value := range in.ByBrowser {
+				if v57First {
+					v57First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v57Name))
+				out.RawByte(':')
+				out.Int64(int64(v57Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ClickStatsResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer28(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ClickStatsResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalServer28(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ClickStatsResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer28(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ClickStatsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalServer28(l, v)
+}
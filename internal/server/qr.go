@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cmrd-a/shortener/internal/qr"
+	"github.com/go-chi/chi/v5"
+)
+
+// qrMaxAge is how long clients may cache a QR code. The encoded short URL never changes,
+// so revalidation with If-None-Match is only needed once it expires.
+const qrMaxAge = 24 * 60 * 60
+
+// QRCodeHandler returns an HTTP handler that renders the full short URL of a link as a QR code.
+// The image is described by the query parameters:
+//
+//	format  png (default) or svg
+//	size    width and height in pixels
+//	margin  quiet zone in modules
+//	level   error correction level: L, M (default), Q or H
+//	fg, bg  foreground and background colours as hex RGB(A), black on white by default
+//
+// Responses carry an ETag of the content and the options and answer If-None-Match with 304.
+func QRCodeHandler(svc Servicer) func(http.ResponseWriter, *http.Request) {
+	return func(res http.ResponseWriter, req *http.Request) {
+		ID := chi.URLParam(req, "linkId")
+		if len(ID) == 0 {
+			http.Error(res, "url is empty", http.StatusBadRequest)
+			return
+		}
+		opts, err := qrOptions(req.URL.Query())
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		shortURL, err := svc.ShortURL(req.Context(), ID)
+		if err != nil {
+			http.Error(res, err.Error(), linkErrorStatus(err))
+			return
+		}
+		etag := opts.ETag(shortURL)
+		if etagMatch(req.Header.Get("If-None-Match"), etag) {
+			setQRCacheHeaders(res, etag)
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
+		var buf bytes.Buffer
+		err = qr.Encode(&buf, shortURL, opts)
+		if errors.Is(err, qr.ErrInvalidOptions) {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		setQRCacheHeaders(res, etag)
+		res.Header().Set("Content-Type", opts.Format.ContentType())
+		res.WriteHeader(http.StatusOK)
+		res.Write(buf.Bytes())
+	}
+}
+
+func setQRCacheHeaders(res http.ResponseWriter, etag string) {
+	res.Header().Set("ETag", etag)
+	res.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", qrMaxAge))
+}
+
+// qrOptions reads the image options from the query, leaving defaults for missing parameters.
+func qrOptions(query url.Values) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	var err error
+	if v := query.Get("format"); v != "" {
+		opts.Format = qr.Format(strings.ToLower(v))
+	}
+	if v := query.Get("size"); v != "" {
+		if opts.Size, err = strconv.Atoi(v); err != nil {
+			return qr.Options{}, fmt.Errorf("bad size: %w", err)
+		}
+	}
+	if v := query.Get("margin"); v != "" {
+		if opts.Margin, err = strconv.Atoi(v); err != nil {
+			return qr.Options{}, fmt.Errorf("bad margin: %w", err)
+		}
+	}
+	if v := query.Get("level"); v != "" {
+		if opts.Level, err = qr.ParseLevel(v); err != nil {
+			return qr.Options{}, err
+		}
+	}
+	if v := query.Get("fg"); v != "" {
+		if opts.Foreground, err = qr.ParseColor(v); err != nil {
+			return qr.Options{}, err
+		}
+	}
+	if v := query.Get("bg"); v != "" {
+		if opts.Background, err = qr.ParseColor(v); err != nil {
+			return qr.Options{}, err
+		}
+	}
+	return opts, opts.Validate()
+}
+
+// etagMatch reports whether an If-None-Match header matches etag, using the weak comparison
+// of RFC 9110 as required for GET.
+func etagMatch(header, etag string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"net/http"
+
 	"github.com/cmrd-a/shortener/internal/server/middleware"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -8,12 +10,27 @@ import (
 
 // Server represents the HTTP server with its router and middleware configuration.
 type Server struct {
-	Router *chi.Mux
+	Router     *chi.Mux
+	adminToken string
+}
+
+// Option configures optional Server settings.
+type Option func(*Server)
+
+// WithAdminToken enables the /api/admin routes for requests bearing the token.
+func WithAdminToken(token string) Option {
+	return func(s *Server) {
+		s.adminToken = token
+	}
 }
 
 // NewServer creates a new Server instance with configured middleware and routes.
-func NewServer(log *zap.Logger, service Servicer) *Server {
-	s := &Server{chi.NewRouter()}
+// The /api/admin routes are only served if an admin token is set.
+func NewServer(log *zap.Logger, service Servicer, opts ...Option) *Server {
+	s := &Server{Router: chi.NewRouter()}
+	for _, opt := range opts {
+		opt(s)
+	}
 	s.Router.Use(
 		middleware.RequestResponseLogger(log),
 		middleware.CheckContentType,
@@ -24,12 +41,38 @@ func NewServer(log *zap.Logger, service Servicer) *Server {
 
 	s.Router.Post("/", AddLinkHandler(service))
 	s.Router.Get("/{linkId}", GetLinkHandler(service))
+	s.Router.Post("/{linkId}", UnlockLinkHandler(service))
+	s.Router.Get("/{linkId}/qr", QRCodeHandler(service))
+	// Extra path segments fill the placeholders of templated links, a single "qr" segment is the
+	// QR code of the link. "api" is a reserved alias, so unknown API paths are not taken for links.
+	s.Router.Get("/{linkId}/*", GetLinkHandler(service))
+	s.Router.Post("/{linkId}/*", UnlockLinkHandler(service))
+	s.Router.Handle("/api/*", http.NotFoundHandler())
 	s.Router.Get("/ping", PingHandler(service))
 
 	s.Router.Post("/api/shorten", ShortenHandler(service))
 	s.Router.Post("/api/shorten/batch", ShortenBatchHandler(service))
 	s.Router.Get("/api/user/urls", GetUserURLsHandler(service))
+	s.Router.Get("/api/user/urls/search", SearchUserURLsHandler(service))
+	s.Router.Get("/api/user/urls/export", ExportUserURLsHandler(service))
+	s.Router.Post("/api/user/urls/import", ImportHandler(service))
 	s.Router.Delete("/api/user/urls", DeleteUserURLsHandler(service))
+	s.Router.Get("/api/user/urls/deletions/{jobId}", DeletionJobHandler(service))
+	s.Router.Get("/api/user/urls/trash", TrashHandler(service))
+	s.Router.Post("/api/user/urls/restore", RestoreUserURLsHandler(service))
+	s.Router.Patch("/api/user/urls/{linkId}", UpdateURLHandler(service))
+	s.Router.Get("/api/user/urls/{linkId}/stats", ClickStatsHandler(service))
+	s.Router.Get("/api/user/urls/{linkId}/history", HistoryHandler(service))
+	s.Router.Post("/api/user/urls/{linkId}/rollback", RollbackHandler(service))
+	s.Router.Put("/api/user/urls/{linkId}/tags", SetTagsHandler(service))
+	s.Router.Get("/api/user/tags", UserTagsHandler(service))
+
+	if s.adminToken != "" {
+		s.Router.Route("/api/admin", func(r chi.Router) {
+			r.Use(middleware.RequireAdminToken(s.adminToken))
+			r.Get("/purge", PurgeStatsHandler(service))
+		})
+	}
 
 	return s
 }
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pages = template.Must(parsePages(""))
+
+// parsePages parses the embedded page templates and then the *.html files of dir, if set.
+// A file in dir named like an embedded page replaces it.
+func parsePages(dir string) (*template.Template, error) {
+	t, err := template.ParseFS(templateFS, "templates/*.html")
+	if err != nil || dir == "" {
+		return t, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil || len(files) == 0 {
+		return t, err
+	}
+	return t.ParseFiles(files...)
+}
+
+// LoadTemplates replaces the page templates with the embedded ones overridden by the *.html files of dir.
+// It must be called before the server starts handling requests.
+func LoadTemplates(dir string) error {
+	t, err := parsePages(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load templates from %s: %w", dir, err)
+	}
+	pages = t
+	return nil
+}
+
+// passwordPage holds the data for the password form of a protected link.
+type passwordPage struct {
+	WrongPassword bool
+}
+
+// previewPage holds the data for the preview of a link's destination.
+type previewPage struct {
+	OriginalURL string
+	Host        string
+	CreatedAt   *time.Time
+	ContinueURL string
+}
+
+// renderPage executes the named page template and writes it with the given status code.
+func renderPage(res http.ResponseWriter, status int, name string, data any) {
+	var buf bytes.Buffer
+	err := pages.ExecuteTemplate(&buf, name, data)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(status)
+	res.Write(buf.Bytes())
+}
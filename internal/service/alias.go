@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Alias length limits.
+const (
+	MinAliasLength = 3
+	MaxAliasLength = 64
+)
+
+// reservedAliases lists slugs that would shadow existing or planned routes.
+var reservedAliases = []string{
+	"api",
+	"ping",
+	"admin",
+	"static",
+	"assets",
+	"health",
+	"metrics",
+	"debug",
+}
+
+// ValidateAlias checks that a custom alias can be used as a short ID.
+// An alias must be MinAliasLength to MaxAliasLength characters long, consist of
+// latin letters, digits, '-' and '_', and must not be a reserved word.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return fmt.Errorf("%w: length must be between %d and %d", ErrInvalidAlias, MinAliasLength, MaxAliasLength)
+	}
+	for _, c := range alias {
+		if !isAliasRune(c) {
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidAlias, c)
+		}
+	}
+	if slices.Contains(reservedAliases, strings.ToLower(alias)) {
+		return fmt.Errorf("%w: %s is reserved", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+func isAliasRune(c rune) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == '-' || c == '_'
+}
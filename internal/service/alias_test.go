@@ -0,0 +1,35 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr bool
+	}{
+		{name: "valid", alias: "spring-sale"},
+		{name: "valid_underscore_digits", alias: "Promo_2025"},
+		{name: "too_short", alias: "ab", wantErr: true},
+		{name: "too_long", alias: strings.Repeat("a", MaxAliasLength+1), wantErr: true},
+		{name: "slash", alias: "spring/sale", wantErr: true},
+		{name: "unicode", alias: "распродажа", wantErr: true},
+		{name: "reserved_ping", alias: "ping", wantErr: true},
+		{name: "reserved_case_insensitive", alias: "API", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAlias(tt.alias)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidAlias)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
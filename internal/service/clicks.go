@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	clickBufferSize   = 4096
+	clickBatchSize    = 256
+	defaultClickFlush = 5 * time.Second
+	directReferrer    = "direct"
+)
+
+// Click describes a single follow of a short link as seen by the HTTP layer.
+type Click struct {
+	ShortID   string
+	Referrer  string
+	UserAgent string
+	IP        string
+}
+
+// WithClickFlushInterval sets how often buffered click events are written to storage.
+// Non-positive intervals are ignored.
+func WithClickFlushInterval(d time.Duration) Option {
+	return func(s *URLService) {
+		if d > 0 {
+			s.clickFlush = d
+		}
+	}
+}
+
+// WithAnonymizeIP makes the service truncate client IPs of click events before storing them.
+func WithAnonymizeIP(anonymize bool) Option {
+	return func(s *URLService) {
+		s.anonymizeIP = anonymize
+	}
+}
+
+// RecordClick queues a click event for storing without blocking the caller.
+// The event is dropped if the queue is full.
+func (s *URLService) RecordClick(click Click) {
+	ip := click.IP
+	if s.anonymizeIP {
+		ip = anonymizeIP(ip)
+	}
+	event := storage.ClickEvent{
+		ShortID:   click.ShortID,
+		ClickedAt: time.Now().UTC(),
+		Referrer:  referrerHost(click.Referrer),
+		UserAgent: click.UserAgent,
+		Browser:   browserFamily(click.UserAgent),
+		IP:        ip,
+	}
+	select {
+	case s.clicksChan <- event:
+	default:
+		s.log.Warn("click queue is full, dropping click", zap.String("short", click.ShortID))
+	}
+}
+
+// GetClickStats returns the aggregated clicks of a link owned by the user.
+func (s *URLService) GetClickStats(ctx context.Context, userID int64, shortID string) (storage.ClickStats, error) {
+	stats, err := s.repository.GetClickStats(ctx, userID, shortID)
+	if err != nil {
+		return storage.ClickStats{}, err
+	}
+	if count, ok := stats.ByReferrer[""]; ok {
+		delete(stats.ByReferrer, "")
+		stats.ByReferrer[directReferrer] += count
+	}
+	return stats, nil
+}
+
+// clicksJob writes buffered click events to storage in batches,
+// either when a batch is full or when the flush interval elapses.
+// When the service context is canceled it writes what is left and stops.
+func (s *URLService) clicksJob() {
+	ticker := time.NewTicker(s.clickFlush)
+	defer ticker.Stop()
+
+	events := make([]storage.ClickEvent, 0, clickBatchSize)
+	flush := func() {
+		if len(events) == 0 {
+			return
+		}
+		err := s.repository.AddClicks(context.Background(), events...)
+		if err != nil {
+			s.log.Error("failed to store clicks", zap.Error(err), zap.Int("count", len(events)))
+		}
+		events = make([]storage.ClickEvent, 0, clickBatchSize)
+	}
+	for {
+		select {
+		case event := <-s.clicksChan:
+			events = append(events, event)
+			if len(events) >= clickBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.ctx.Done():
+			for {
+				select {
+				case event := <-s.clicksChan:
+					events = append(events, event)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// anonymizeIP zeroes the host part of an IP address: the last octet of IPv4
+// and everything after the first 48 bits of IPv6. Unparsable input is dropped.
+func anonymizeIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	bits := 48
+	if addr.Is4() || addr.Is4In6() {
+		addr = addr.Unmap()
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
+
+// referrerHost reduces a Referer header to its host, so that stats are grouped by site.
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// browserFamilies maps User-Agent tokens to browser names.
+// Order matters: Chromium-based browsers also announce Chrome and Safari.
+var browserFamilies = []struct {
+	token string
+	name  string
+}{
+	{"bot", "Bot"},
+	{"spider", "Bot"},
+	{"crawler", "Bot"},
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"yabrowser/", "Yandex"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+// browserFamily returns the browser name for a User-Agent header.
+func browserFamily(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown"
+	}
+	ua := strings.ToLower(userAgent)
+	for _, f := range browserFamilies {
+		if strings.Contains(ua, f.token) {
+			return f.name
+		}
+	}
+	return "Other"
+}
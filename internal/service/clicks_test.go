@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowserFamily(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Chrome"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15", "Safari"},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "Bot"},
+		{"curl/8.5.0", "curl"},
+		{"", "Unknown"},
+		{"SomethingElse/1.0", "Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			require.Equal(t, tt.want, browserFamily(tt.userAgent))
+		})
+	}
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	require.Equal(t, "192.168.10.0", anonymizeIP("192.168.10.77"))
+	require.Equal(t, "192.168.10.0", anonymizeIP("::ffff:192.168.10.77"))
+	require.Equal(t, "2001:db8:abcd::", anonymizeIP("2001:db8:abcd:12:34::1"))
+	require.Equal(t, "", anonymizeIP("not-an-ip"))
+}
+
+func TestReferrerHost(t *testing.T) {
+	require.Equal(t, "news.example.com", referrerHost("https://News.Example.com:8443/article?id=1"))
+	require.Equal(t, "", referrerHost(""))
+	require.Equal(t, "", referrerHost("not a url"))
+}
+
+func TestRecordClick(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo,
+		WithClickFlushInterval(10*time.Millisecond),
+		WithAnonymizeIP(true),
+	)
+	_, err := svc.Shorten(ctx, "https://tracked.example", 1, ShortenOptions{Alias: "tracked"})
+	require.NoError(t, err)
+
+	svc.RecordClick(Click{ShortID: "tracked", Referrer: "https://news.example/a", UserAgent: "curl/8.5.0", IP: "10.1.2.3"})
+	svc.RecordClick(Click{ShortID: "tracked", IP: "10.1.2.4"})
+
+	require.Eventually(t, func() bool {
+		stats, err := svc.GetClickStats(ctx, 1, "tracked")
+		return err == nil && stats.Total == 2
+	}, time.Second, 10*time.Millisecond)
+
+	stats, err := svc.GetClickStats(ctx, 1, "tracked")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"news.example": 1, "direct": 1}, stats.ByReferrer)
+	require.Equal(t, map[string]int64{"curl": 1, "Unknown": 1}, stats.ByBrowser)
+
+	_, err = svc.GetClickStats(ctx, 2, "tracked")
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeletionBufferSize    = 1024
+	defaultDeletionFlushInterval = 5 * time.Second
+	defaultDeletionBatchSize     = 1000
+	// deletionJobTTL is how long finished deletion jobs can be looked up.
+	deletionJobTTL = 24 * time.Hour
+)
+
+// DeletionStatus is the state of a deletion job.
+type DeletionStatus string
+
+// Deletion job states. A job is partially applied if some of its short IDs were skipped
+// because they do not exist or belong to another user, and failed if the storage returned an error.
+const (
+	DeletionQueued           DeletionStatus = "queued"
+	DeletionApplied          DeletionStatus = "applied"
+	DeletionPartiallyApplied DeletionStatus = "partially_applied"
+	DeletionFailed           DeletionStatus = "failed"
+)
+
+// DeletionItem is the outcome of deleting a single short ID, empty while the job is queued.
+type DeletionItem struct {
+	ShortID string
+	Outcome storage.DeletionOutcome
+}
+
+// DeletionJob tracks a request to delete links of a user. AppliedAt is zero while the job is queued.
+// Jobs are kept in memory, so they can not be looked up after a restart even if the deletion itself is durable.
+type DeletionJob struct {
+	ID        string
+	UserID    int64
+	Status    DeletionStatus
+	CreatedAt time.Time
+	AppliedAt time.Time
+	Items     []DeletionItem
+}
+
+// deletionRequest is a deletion job on its way to the storage; replayed requests have no job
+// and do not take room in the deletion buffer.
+type deletionRequest struct {
+	jobID    string
+	urls     []storage.URLForDelete
+	buffered bool
+}
+
+// WithDeletionBuffer sets how many short IDs can wait for deletion before new requests are rejected.
+func WithDeletionBuffer(n int) Option {
+	return func(s *URLService) {
+		s.deletionBuffer = n
+	}
+}
+
+// WithDeletionFlushInterval sets how often waiting deletions are applied.
+func WithDeletionFlushInterval(d time.Duration) Option {
+	return func(s *URLService) {
+		s.deletionFlush = d
+	}
+}
+
+// WithDeletionBatchSize sets how many short IDs are deleted by a single storage call.
+// Waiting deletions are also applied as soon as there are that many of them.
+func WithDeletionBatchSize(n int) Option {
+	return func(s *URLService) {
+		s.deletionBatch = n
+	}
+}
+
+// deletionJobs holds the deletion jobs guarded by a mutex.
+type deletionJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*DeletionJob
+}
+
+// DeleteUserURLs queues URLs for deletion and returns the ID of the deletion job.
+// The actual deletion is handled asynchronously by a background goroutine, see GetDeletionJob.
+// With a deletion queue the requests are persisted first, so they are applied even after a crash.
+// It never blocks on a saturated deletion buffer but returns a DeletionQueueFullError instead,
+// and ErrTooManyDeletions if the request alone exceeds the buffer.
+func (s *URLService) DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string) (string, error) {
+	if s.ctx.Err() != nil {
+		return "", ErrShuttingDown
+	}
+	if len(shortIDs) > s.deletionBuffer {
+		return "", fmt.Errorf("%w: at most %d short IDs per request", ErrTooManyDeletions, s.deletionBuffer)
+	}
+	if s.delWaiting.Add(int64(len(shortIDs))) > int64(s.deletionBuffer) {
+		s.delWaiting.Add(-int64(len(shortIDs)))
+		return "", &DeletionQueueFullError{RetryAfter: s.deletionFlush}
+	}
+	job := &DeletionJob{
+		ID:        rand.Text(),
+		UserID:    userID,
+		Status:    DeletionQueued,
+		CreatedAt: time.Now(),
+		Items:     make([]DeletionItem, len(shortIDs)),
+	}
+	urls := make([]storage.URLForDelete, len(shortIDs))
+	for i, shortID := range shortIDs {
+		urls[i] = storage.URLForDelete{UserID: userID, ShortID: shortID}
+		job.Items[i].ShortID = shortID
+	}
+	if len(urls) == 0 {
+		job.Status = DeletionApplied
+		job.AppliedAt = job.CreatedAt
+	}
+
+	s.deletionJobs.mu.Lock()
+	s.deletionJobs.jobs[job.ID] = job
+	s.deletionJobs.mu.Unlock()
+	if len(urls) == 0 {
+		return job.ID, nil
+	}
+	if s.deletionQueue != nil {
+		if err := s.deletionQueue.PushDeletions(ctx, urls...); err != nil {
+			s.dropDeletionJob(job.ID, len(urls))
+			return "", err
+		}
+	}
+	// Every buffered request holds at least one short ID of the buffer, so the channel never fills up first.
+	select {
+	case s.delUserURLsChan <- deletionRequest{jobID: job.ID, urls: urls, buffered: true}:
+		return job.ID, nil
+	default:
+		s.dropDeletionJob(job.ID, len(urls))
+		return "", &DeletionQueueFullError{RetryAfter: s.deletionFlush}
+	}
+}
+
+// dropDeletionJob forgets a job that could not be queued and frees its room in the deletion buffer.
+func (s *URLService) dropDeletionJob(jobID string, size int) {
+	s.delWaiting.Add(-int64(size))
+	s.deletionJobs.mu.Lock()
+	delete(s.deletionJobs.jobs, jobID)
+	s.deletionJobs.mu.Unlock()
+}
+
+// GetDeletionJob returns a deletion job of the user.
+// It returns ErrDeletionJobNotFound for unknown, foreign and long finished jobs.
+func (s *URLService) GetDeletionJob(ctx context.Context, userID int64, jobID string) (DeletionJob, error) {
+	s.deletionJobs.mu.Lock()
+	defer s.deletionJobs.mu.Unlock()
+	job, ok := s.deletionJobs.jobs[jobID]
+	if !ok || job.UserID != userID {
+		return DeletionJob{}, ErrDeletionJobNotFound
+	}
+	snapshot := *job
+	snapshot.Items = slices.Clone(job.Items)
+	return snapshot, nil
+}
+
+// finishDeletionJobs records the outcomes of the flushed requests in their jobs.
+// Nil outcomes mean the deletion failed.
+func (s *URLService) finishDeletionJobs(requests []deletionRequest, outcomes []storage.DeletionOutcome) {
+	now := time.Now()
+	s.deletionJobs.mu.Lock()
+	defer s.deletionJobs.mu.Unlock()
+	offset := 0
+	for _, req := range requests {
+		job, ok := s.deletionJobs.jobs[req.jobID]
+		if !ok {
+			offset += len(req.urls)
+			continue
+		}
+		job.AppliedAt = now
+		if outcomes == nil {
+			job.Status = DeletionFailed
+			continue
+		}
+		job.Status = DeletionApplied
+		for i := range job.Items {
+			job.Items[i].Outcome = outcomes[offset+i]
+			if job.Items[i].Outcome != storage.OutcomeDeleted && job.Items[i].Outcome != storage.OutcomeAlreadyDeleted {
+				job.Status = DeletionPartiallyApplied
+			}
+		}
+		offset += len(req.urls)
+	}
+}
+
+// pruneDeletionJobs forgets the jobs finished longer than deletionJobTTL ago.
+func (s *URLService) pruneDeletionJobs(now time.Time) {
+	s.deletionJobs.mu.Lock()
+	defer s.deletionJobs.mu.Unlock()
+	for id, job := range s.deletionJobs.jobs {
+		if !job.AppliedAt.IsZero() && now.Sub(job.AppliedAt) > deletionJobTTL {
+			delete(s.deletionJobs.jobs, id)
+		}
+	}
+}
+
+// deleteUserURLsJob applies queued deletions every flush interval or as soon as a batch is full,
+// replaying the pending ones of the deletion queue first. When the service context is canceled
+// it applies what is left and stops. Failed deletions stay in the deletion queue and are replayed on the next start.
+func (s *URLService) deleteUserURLsJob() {
+	ticker := time.NewTicker(s.deletionFlush)
+	defer ticker.Stop()
+
+	var requests []deletionRequest
+	waiting := 0
+	if s.deletionQueue != nil {
+		pending, err := s.deletionQueue.PendingDeletions(s.ctx)
+		if err != nil {
+			s.log.Error("failed to read pending deletions", zap.Error(err))
+		}
+		if len(pending) > 0 {
+			s.log.Info("replaying pending deletions", zap.Int("count", len(pending)))
+			requests = append(requests, deletionRequest{urls: pending})
+			waiting = len(pending)
+		}
+	}
+	flush := func() {
+		if len(requests) == 0 {
+			return
+		}
+		var urls []storage.URLForDelete
+		var buffered int64
+		for _, req := range requests {
+			urls = append(urls, req.urls...)
+			if req.buffered {
+				buffered += int64(len(req.urls))
+			}
+		}
+		defer func() {
+			s.delWaiting.Add(-buffered)
+			requests = nil
+			waiting = 0
+		}()
+		outcomes := make([]storage.DeletionOutcome, 0, len(urls))
+		for batch := range slices.Chunk(urls, s.deletionBatch) {
+			batchOutcomes, err := s.repository.MarkDeletedUserURLs(context.Background(), batch...)
+			if err != nil {
+				s.log.Error("failed to delete urls", zap.Error(err), zap.Int("count", len(urls)))
+				s.finishDeletionJobs(requests, nil)
+				return
+			}
+			outcomes = append(outcomes, batchOutcomes...)
+		}
+		if s.deletionQueue != nil {
+			if err := s.deletionQueue.RemoveDeletions(context.Background(), urls...); err != nil {
+				s.log.Error("failed to remove applied deletions", zap.Error(err))
+			}
+		}
+		s.finishDeletionJobs(requests, outcomes)
+	}
+	for {
+		select {
+		case req := <-s.delUserURLsChan:
+			requests = append(requests, req)
+			waiting += len(req.urls)
+			if waiting >= s.deletionBatch {
+				flush()
+			}
+		case now := <-ticker.C:
+			flush()
+			s.pruneDeletionJobs(now)
+		case <-s.ctx.Done():
+			for {
+				select {
+				case req := <-s.delUserURLsChan:
+					requests = append(requests, req)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -1,5 +1,50 @@
 package service
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrShortIDExhausted is returned when no free short ID could be allocated within the retry limit.
+var ErrShortIDExhausted = errors.New("failed to allocate a free short id")
+
+// ErrInvalidAlias is returned when a custom alias does not pass validation.
+var ErrInvalidAlias = errors.New("invalid alias")
+
+// ErrInvalidExpiry is returned when the requested expiry is contradictory or already in the past.
+var ErrInvalidExpiry = errors.New("invalid expiry")
+
+// ErrInvalidMaxClicks is returned when the requested click limit is negative.
+var ErrInvalidMaxClicks = errors.New("invalid max clicks")
+
+// ErrInvalidPassword is returned when a link password cannot be used.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrPasswordRequired is returned when a password-protected link is followed without a password.
+var ErrPasswordRequired = errors.New("password required")
+
+// ErrWrongPassword is returned when a password-protected link is unlocked with a wrong password.
+var ErrWrongPassword = errors.New("wrong password")
+
+// ErrAliasTaken is returned when a custom alias is already used by another link.
+var ErrAliasTaken = errors.New("alias is already taken")
+
+// AliasTakenError is returned when a batch fails because one of its aliases is already used by another link.
+// It matches ErrAliasTaken.
+type AliasTakenError struct {
+	Alias string
+}
+
+// Error returns the message of ErrAliasTaken with the alias.
+func (e *AliasTakenError) Error() string {
+	return ErrAliasTaken.Error() + ": " + e.Alias
+}
+
+// Unwrap returns ErrAliasTaken.
+func (e *AliasTakenError) Unwrap() error {
+	return ErrAliasTaken
+}
+
 // OriginalExistError represents an error when trying to shorten a URL that already exists.
 type OriginalExistError struct {
 	Short string
@@ -16,3 +61,59 @@ func NewOriginalExistError(short string) error {
 		Short: short,
 	}
 }
+
+// ErrInvalidURL is returned when an original URL fails validation.
+var ErrInvalidURL = errors.New("invalid url")
+
+// ErrURLBlocked is returned when an original URL matches the denylist.
+var ErrURLBlocked = errors.New("url is blocked")
+
+// ErrInvalidRedirectType is returned when a link is created with an unsupported redirect status code.
+var ErrInvalidRedirectType = errors.New("invalid redirect type")
+
+// ErrInvalidQueryPolicy is returned when a link is created with an unknown query policy.
+var ErrInvalidQueryPolicy = errors.New("invalid query policy")
+
+// ErrInvalidTarget is returned when a request for a templated link does not fill its placeholders.
+var ErrInvalidTarget = errors.New("invalid target")
+
+// ErrInvalidTitle is returned when a link title is too long or not valid UTF-8.
+var ErrInvalidTitle = errors.New("invalid title")
+
+// ErrInvalidNotes is returned when link notes are too long or not valid UTF-8.
+var ErrInvalidNotes = errors.New("invalid notes")
+
+// ErrInvalidTag is returned when a link tag does not pass validation.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrInvalidQuery is returned when a URL list query has invalid filters, sort order, limit or cursor.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrTooManyImportRows is returned when an import file has more than MaxImportRows rows.
+var ErrTooManyImportRows = errors.New("too many rows to import")
+
+// ErrEmptyUpdate is returned when a link update does not change anything.
+var ErrEmptyUpdate = errors.New("nothing to update")
+
+// ErrRevisionNotFound is returned when rolling back to a revision the link does not have.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrShuttingDown is returned when a request can not be queued because the service is stopping.
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// ErrDeletionJobNotFound is returned when a deletion job is unknown or belongs to another user.
+var ErrDeletionJobNotFound = errors.New("deletion job not found")
+
+// ErrTooManyDeletions is returned when a single deletion request exceeds the deletion buffer.
+var ErrTooManyDeletions = errors.New("too many short ids")
+
+// DeletionQueueFullError is returned when the deletion buffer can not take more short IDs.
+// RetryAfter is how long it takes until waiting deletions are applied and the buffer frees up.
+type DeletionQueueFullError struct {
+	RetryAfter time.Duration
+}
+
+// Error returns a message saying that the deletion queue is full.
+func (e *DeletionQueueFullError) Error() string {
+	return "deletion queue is full"
+}
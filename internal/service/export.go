@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"iter"
+)
+
+// ExportedURL is a URL of a user with the number of times it was followed.
+type ExportedURL struct {
+	SvcURL
+	Clicks int64
+}
+
+// ExportUserURLs streams the live URLs of a user in the order they were created.
+// The sequence ends after the first error.
+func (s *URLService) ExportUserURLs(ctx context.Context, userID int64) iter.Seq2[ExportedURL, error] {
+	return func(yield func(ExportedURL, error) bool) {
+		for export, err := range s.repository.ExportUserURLs(ctx, userID) {
+			if err != nil {
+				yield(ExportedURL{}, err)
+				return
+			}
+			if !yield(ExportedURL{SvcURL: s.svcURL(export.StoredURL), Clicks: export.Clicks}, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/config"
+)
+
+// Names of the short ID strategies accepted by MakeGenerator.
+const (
+	GeneratorLetters = "letters"
+	GeneratorRandom  = "random"
+	GeneratorCounter = "counter"
+	GeneratorSeeded  = "seeded"
+)
+
+var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Generator defines an interface for generating short URL identifiers.
+type Generator interface {
+	Generate() string
+}
+
+// Extender is implemented by generators that can produce longer identifiers on demand.
+// URLService uses it to escalate the identifier length after a short ID collision.
+type Extender interface {
+	GenerateExtended(extra int) string
+}
+
+// MakeGenerator creates a Generator based on the provided configuration.
+// It returns an error if the configured strategy is unknown.
+//
+// The counter strategy is not persisted: every start seeds the counter with the current Unix time
+// in milliseconds, which is ahead of the values used before unless the clock goes back or more than
+// a thousand IDs per second were generated. An ID that still turns out to be taken is regenerated
+// by the collision retry of URLService.
+func MakeGenerator(cfg *config.Config) (Generator, error) {
+	if cfg.IDLength <= 0 {
+		return nil, fmt.Errorf("id length must be positive, got %d", cfg.IDLength)
+	}
+	switch cfg.IDGenerator {
+	case GeneratorLetters, "":
+		return NewLetterGenerator(cfg.IDLength), nil
+	case GeneratorRandom:
+		return NewRandomGenerator(cfg.IDLength), nil
+	case GeneratorCounter:
+		return NewCounterGenerator(uint64(time.Now().UnixMilli()), cfg.IDLength, cfg.IDSeed), nil
+	case GeneratorSeeded:
+		return NewSeededGenerator(cfg.IDSeed, cfg.IDLength), nil
+	}
+	return nil, fmt.Errorf("unknown id generator %q", cfg.IDGenerator)
+}
+
+// defaultLetterLength is the identifier length of NewShortGenerator.
+const defaultLetterLength = 5
+
+// ShortGenerator implements the Generator interface using random letter combinations.
+type ShortGenerator struct {
+	length int
+}
+
+// NewShortGenerator creates a new ShortGenerator producing 5-character identifiers.
+func NewShortGenerator() *ShortGenerator {
+	return NewLetterGenerator(defaultLetterLength)
+}
+
+// NewLetterGenerator creates a new ShortGenerator producing identifiers of the given length.
+func NewLetterGenerator(length int) *ShortGenerator {
+	return &ShortGenerator{length: length}
+}
+
+// Generate creates a random string of letters of the configured length.
+func (g *ShortGenerator) Generate() string {
+	return g.GenerateExtended(0)
+}
+
+// GenerateExtended creates a random string of letters that is extra characters longer than the configured length.
+func (g *ShortGenerator) GenerateExtended(extra int) string {
+	b := make([]rune, g.length+extra)
+	for i := range b {
+		b[i] = letterRunes[mrand.Intn(len(letterRunes))]
+	}
+	return string(b)
+}
+
+// RandomGenerator generates base62 identifiers using a cryptographically secure source.
+type RandomGenerator struct {
+	length int
+}
+
+// NewRandomGenerator creates a new RandomGenerator producing identifiers of the given length.
+func NewRandomGenerator(length int) *RandomGenerator {
+	return &RandomGenerator{length: length}
+}
+
+// Generate creates a random base62 string read from crypto/rand.
+func (g *RandomGenerator) Generate() string {
+	return g.GenerateExtended(0)
+}
+
+// GenerateExtended creates a random base62 string that is extra characters longer than the configured length.
+func (g *RandomGenerator) GenerateExtended(extra int) string {
+	b := make([]byte, g.length+extra)
+	limit := big.NewInt(int64(len(base62Alphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			// crypto/rand never fails on supported platforms
+			panic(err)
+		}
+		b[i] = base62Alphabet[n.Int64()]
+	}
+	return string(b)
+}
+
+// SeededGenerator generates base62 identifiers from a seeded pseudo-random source.
+// The same seed always yields the same sequence, which makes it suitable for tests.
+type SeededGenerator struct {
+	length int
+	rnd    *randv2.Rand
+	mu     *sync.Mutex
+}
+
+// NewSeededGenerator creates a new SeededGenerator with the given seed and identifier length.
+func NewSeededGenerator(seed int64, length int) *SeededGenerator {
+	return &SeededGenerator{
+		length: length,
+		rnd:    randv2.New(randv2.NewPCG(uint64(seed), uint64(seed))),
+		mu:     &sync.Mutex{},
+	}
+}
+
+// Generate returns the next identifier of the seeded sequence.
+func (g *SeededGenerator) Generate() string {
+	return g.GenerateExtended(0)
+}
+
+// GenerateExtended returns the next identifier of the seeded sequence, extra characters longer than the configured length.
+func (g *SeededGenerator) GenerateExtended(extra int) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := make([]byte, g.length+extra)
+	for i := range b {
+		b[i] = base62Alphabet[g.rnd.IntN(len(base62Alphabet))]
+	}
+	return string(b)
+}
+
+// CounterGenerator encodes a monotonic counter as an obfuscated base62 identifier
+// in the spirit of Sqids: consecutive values do not look consecutive, and every
+// identifier is padded to a minimum length.
+type CounterGenerator struct {
+	counter   atomic.Uint64
+	minLength int
+	alphabet  []byte
+}
+
+// NewCounterGenerator creates a new CounterGenerator starting at start.
+// The counter lives in memory only, see MakeGenerator for how it is seeded.
+// The salt shuffles the alphabet so that different deployments produce different identifiers.
+func NewCounterGenerator(start uint64, minLength int, salt int64) *CounterGenerator {
+	alphabet := []byte(base62Alphabet)
+	rnd := randv2.New(randv2.NewPCG(uint64(salt), uint64(salt)))
+	rnd.Shuffle(len(alphabet), func(i, j int) {
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	})
+	g := &CounterGenerator{
+		minLength: minLength,
+		alphabet:  shuffleAlphabet(alphabet),
+	}
+	g.counter.Store(start)
+	return g
+}
+
+// Generate encodes the next counter value.
+func (g *CounterGenerator) Generate() string {
+	return g.GenerateExtended(0)
+}
+
+// GenerateExtended encodes the next counter value padded to extra characters beyond the minimum length.
+func (g *CounterGenerator) GenerateExtended(extra int) string {
+	return g.encode(g.counter.Add(1), g.minLength+extra)
+}
+
+func (g *CounterGenerator) encode(n uint64, minLength int) string {
+	size := len(g.alphabet)
+	offset := (int(g.alphabet[n%uint64(size)]) + 1) % size
+
+	alphabet := make([]byte, 0, size)
+	alphabet = append(alphabet, g.alphabet[offset:]...)
+	alphabet = append(alphabet, g.alphabet[:offset]...)
+
+	prefix := alphabet[0]
+	for i, j := 0, size-1; i < j; i, j = i+1, j-1 {
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+
+	id := append([]byte{prefix}, toBase(n, alphabet[1:])...)
+	if len(id) < minLength {
+		id = append(id, alphabet[0])
+		for len(id) < minLength {
+			alphabet = shuffleAlphabet(alphabet)
+			id = append(id, alphabet[:min(minLength-len(id), size)]...)
+		}
+	}
+	return string(id)
+}
+
+// toBase converts n to its representation in the given alphabet, most significant digit first.
+func toBase(n uint64, alphabet []byte) []byte {
+	size := uint64(len(alphabet))
+	var id []byte
+	for {
+		id = append([]byte{alphabet[n%size]}, id...)
+		n /= size
+		if n == 0 {
+			return id
+		}
+	}
+}
+
+// shuffleAlphabet returns a deterministic permutation of the alphabet.
+func shuffleAlphabet(alphabet []byte) []byte {
+	chars := make([]byte, len(alphabet))
+	copy(chars, alphabet)
+	for i, j := 0, len(chars)-1; j > 0; i, j = i+1, j-1 {
+		r := (i*j + int(chars[i]) + int(chars[j])) % len(chars)
+		chars[i], chars[r] = chars[r], chars[i]
+	}
+	return chars
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cmrd-a/shortener/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		length   int
+		wantErr  bool
+	}{
+		{name: "letters", strategy: GeneratorLetters, length: 5},
+		{name: "letters_length", strategy: GeneratorLetters, length: 9},
+		{name: "default", strategy: "", length: 5},
+		{name: "random", strategy: GeneratorRandom, length: 8},
+		{name: "counter", strategy: GeneratorCounter, length: 6},
+		{name: "seeded", strategy: GeneratorSeeded, length: 7},
+		{name: "unknown", strategy: "uuid", length: 5, wantErr: true},
+		{name: "zero_length", strategy: GeneratorRandom, length: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := MakeGenerator(&config.Config{IDGenerator: tt.strategy, IDLength: tt.length})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			id := g.Generate()
+			require.GreaterOrEqual(t, len(id), tt.length)
+			for _, c := range id {
+				require.True(t, strings.ContainsRune(base62Alphabet, c), "unexpected char %q in %s", c, id)
+			}
+		})
+	}
+}
+
+func TestLetterGeneratorLength(t *testing.T) {
+	g, err := MakeGenerator(&config.Config{IDGenerator: GeneratorLetters, IDLength: 9})
+	require.NoError(t, err)
+	require.Len(t, g.Generate(), 9)
+	require.Len(t, g.(Extender).GenerateExtended(2), 11)
+	require.Len(t, NewShortGenerator().Generate(), 5)
+}
+
+func TestSeededGeneratorIsDeterministic(t *testing.T) {
+	g1 := NewSeededGenerator(42, 8)
+	g2 := NewSeededGenerator(42, 8)
+	g3 := NewSeededGenerator(43, 8)
+	for range 10 {
+		id := g1.Generate()
+		require.Len(t, id, 8)
+		require.Equal(t, id, g2.Generate())
+		require.NotEqual(t, id, g3.Generate())
+	}
+}
+
+func TestCounterGeneratorIsUnique(t *testing.T) {
+	g := NewCounterGenerator(0, 6, 1)
+	seen := make(map[string]struct{})
+	prev := ""
+	for range 10000 {
+		id := g.Generate()
+		require.GreaterOrEqual(t, len(id), 6)
+		require.NotEqual(t, prev, id)
+		_, dup := seen[id]
+		require.False(t, dup, "duplicate id %s", id)
+		seen[id] = struct{}{}
+		prev = id
+	}
+}
+
+func TestCounterGeneratorSaltChangesIDs(t *testing.T) {
+	g1 := NewCounterGenerator(100, 6, 1)
+	g2 := NewCounterGenerator(100, 6, 2)
+	require.NotEqual(t, g1.Generate(), g2.Generate())
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MaxImportRows limits the number of rows of a single import.
+const MaxImportRows = 10000
+
+// importChunkSize is how many rows of an import are shortened by a single ShortenBatch call.
+const importChunkSize = 500
+
+// ImportStatus is the outcome of importing a single row.
+type ImportStatus string
+
+// Import outcomes.
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportDuplicate ImportStatus = "duplicate"
+	ImportRejected  ImportStatus = "rejected"
+)
+
+// ImportRow is a link read from an import file. Line is the position of the row in the file.
+// Err is set if the row could not be parsed; such a row is rejected with it.
+type ImportRow struct {
+	Line int
+	BatchItem
+	Err error
+}
+
+// ImportResult is the outcome of importing a row. ShortURL is the created link or, for a duplicate,
+// the link already stored for the same original; Err explains why the row was rejected.
+type ImportResult struct {
+	Line     int
+	Status   ImportStatus
+	ShortURL string
+	Err      error
+}
+
+// Import shortens the rows of an import file in chunks through ShortenBatch and returns the outcome
+// of each row, in the order of the rows. Invalid rows and rows whose alias is taken or repeated are
+// rejected, rows whose original is already stored within the dedup scope are reported as duplicates.
+// Later rows with the original of an earlier one are treated the same way, once the earlier row is stored.
+func (s *URLService) Import(ctx context.Context, userID int64, rows []ImportRow) ([]ImportResult, error) {
+	if len(rows) > MaxImportRows {
+		return nil, fmt.Errorf("%w: at most %d rows can be imported at once", ErrTooManyImportRows, MaxImportRows)
+	}
+	now := time.Now()
+	results := make([]ImportResult, len(rows))
+	items := make([]BatchItem, len(rows))
+	aliases := make(map[string]struct{})
+	pending := make([]int, 0, len(rows))
+	for i, row := range rows {
+		results[i] = ImportResult{Line: row.Line, Status: ImportRejected, Err: row.Err}
+		if row.Err != nil {
+			continue
+		}
+		stored, err := s.prepare(userID, row.BatchItem, now)
+		if err == nil && row.Alias != "" {
+			if _, ok := aliases[row.Alias]; ok {
+				err = fmt.Errorf("%w: %s is used twice", ErrInvalidAlias, row.Alias)
+			}
+			aliases[row.Alias] = struct{}{}
+		}
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		items[i] = row.BatchItem
+		items[i].OriginalURL = stored.OriginalURL
+		pending = append(pending, i)
+	}
+
+	for len(pending) > 0 {
+		var chunk, deferred []int
+		originals := make(map[string]struct{})
+		for _, i := range pending {
+			_, repeated := originals[items[i].OriginalURL]
+			if repeated || len(chunk) == importChunkSize {
+				deferred = append(deferred, i)
+				continue
+			}
+			originals[items[i].OriginalURL] = struct{}{}
+			chunk = append(chunk, i)
+		}
+		if err := s.importChunk(ctx, userID, items, chunk, results); err != nil {
+			return nil, err
+		}
+		pending = deferred
+	}
+	return results, nil
+}
+
+// importChunk stores the rows of a chunk, which all have different originals, and records their outcomes.
+// A row is reported as created only if the batch stored it, so a row whose original was stored
+// concurrently is a duplicate too.
+func (s *URLService) importChunk(ctx context.Context, userID int64, items []BatchItem, chunk []int, results []ImportResult) error {
+	// Zero-padded row indexes keep the batch in the order of the rows.
+	batch := make(map[string]BatchItem, len(chunk))
+	for _, i := range chunk {
+		batch[fmt.Sprintf("%08d", i)] = items[i]
+	}
+	for len(batch) > 0 {
+		stored, err := s.shortenBatch(ctx, userID, batch)
+		var taken *AliasTakenError
+		if errors.As(err, &taken) {
+			for corrID, item := range batch {
+				if item.Alias == taken.Alias {
+					i, _ := strconv.Atoi(corrID)
+					results[i].Err = err
+					delete(batch, corrID)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for corrID, item := range stored {
+			i, _ := strconv.Atoi(corrID)
+			status := ImportDuplicate
+			if item.created {
+				status = ImportCreated
+			}
+			results[i] = ImportResult{Line: results[i].Line, Status: status, ShortURL: s.addBaseURL(item.short)}
+		}
+		break
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	_, err := svc.Shorten(ctx, "https://existing.example/", 2, ShortenOptions{Alias: "taken"})
+	require.NoError(t, err)
+
+	parseErr := errors.New("bad row")
+	rows := []ImportRow{
+		{Line: 2, BatchItem: BatchItem{OriginalURL: "https://new.example/", ShortenOptions: ShortenOptions{Alias: "fresh", Tags: []string{"Imported"}}}},
+		{Line: 3, BatchItem: BatchItem{OriginalURL: "HTTPS://EXISTING.example/"}},
+		{Line: 4, BatchItem: BatchItem{OriginalURL: "https://NEW.example/"}},
+		{Line: 5, BatchItem: BatchItem{OriginalURL: "javascript:alert(1)"}},
+		{Line: 6, BatchItem: BatchItem{OriginalURL: "https://other.example/", ShortenOptions: ShortenOptions{Alias: "taken"}}},
+		{Line: 7, BatchItem: BatchItem{OriginalURL: "https://third.example/", ShortenOptions: ShortenOptions{Alias: "fresh"}}},
+		{Line: 8, Err: parseErr},
+		{Line: 9, BatchItem: BatchItem{OriginalURL: "https://fourth.example/"}},
+	}
+	results, err := svc.Import(ctx, 1, rows)
+	require.NoError(t, err)
+	require.Len(t, results, len(rows))
+
+	require.Equal(t, ImportResult{Line: 2, Status: ImportCreated, ShortURL: "localhost/fresh"}, results[0])
+	require.Equal(t, ImportResult{Line: 3, Status: ImportDuplicate, ShortURL: "localhost/taken"}, results[1])
+	require.Equal(t, ImportResult{Line: 4, Status: ImportDuplicate, ShortURL: "localhost/fresh"}, results[2])
+	require.Equal(t, ImportRejected, results[3].Status)
+	require.ErrorIs(t, results[3].Err, ErrInvalidURL)
+	require.ErrorIs(t, results[4].Err, ErrAliasTaken)
+	require.ErrorIs(t, results[5].Err, ErrInvalidAlias)
+	require.ErrorIs(t, results[6].Err, parseErr)
+	require.Equal(t, ImportCreated, results[7].Status)
+
+	page, err := svc.GetUserURLs(ctx, 1, storage.URLQuery{Tag: "imported"})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, 1)
+
+	_, err = svc.Import(ctx, 1, make([]ImportRow, MaxImportRows+1))
+	require.ErrorIs(t, err, ErrTooManyImportRows)
+}
+
+func TestImportChunks(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository(storage.WithDedupScope(storage.DedupOff)))
+	rows := make([]ImportRow, importChunkSize+10)
+	for i := range rows {
+		rows[i] = ImportRow{Line: i + 1, BatchItem: BatchItem{OriginalURL: fmt.Sprintf("https://site%d.example/", i%(importChunkSize+5))}}
+	}
+	results, err := svc.Import(ctx, 1, rows)
+	require.NoError(t, err)
+	for _, r := range results {
+		require.Equal(t, ImportCreated, r.Status, r)
+	}
+	page, err := svc.GetUserURLs(ctx, 1, storage.URLQuery{Limit: MaxPageSize})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, len(rows))
+}
+
+// racingRepository stores the original of a batch under another short ID right before the batch,
+// as a concurrent request would do between the dedup lookup and the insert.
+type racingRepository struct {
+	storage.Repository
+	raced bool
+}
+
+func (r *racingRepository) AddBatch(ctx context.Context, userID int64, batch ...storage.StoredURL) error {
+	if !r.raced {
+		r.raced = true
+		if err := r.Repository.Add(ctx, storage.StoredURL{ShortID: "racer", OriginalURL: batch[0].OriginalURL, UserID: userID}); err != nil {
+			return err
+		}
+	}
+	return r.Repository.AddBatch(ctx, userID, batch...)
+}
+
+func TestImportConcurrentDuplicate(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", &racingRepository{Repository: storage.NewInMemoryRepository()})
+	rows := []ImportRow{{Line: 2, BatchItem: BatchItem{OriginalURL: "https://raced.example/"}}}
+	results, err := svc.Import(ctx, 1, rows)
+	require.NoError(t, err)
+	require.Equal(t, ImportResult{Line: 2, Status: ImportDuplicate, ShortURL: "localhost/racer"}, results[0])
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownFlushesDeletionsAndClicks(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo, WithContext(ctx))
+	for _, alias := range []string{"gone", "kept"} {
+		_, err := svc.Shorten(context.TODO(), "https://"+alias+".example/", 1, ShortenOptions{Alias: alias})
+		require.NoError(t, err)
+	}
+
+	jobID, err := svc.DeleteUserURLs(context.TODO(), 1, "gone")
+	require.NoError(t, err)
+	svc.RecordClick(Click{ShortID: "kept"})
+	stop()
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.Wait(waitCtx))
+
+	_, err = repo.Get(context.TODO(), "gone")
+	require.ErrorIs(t, err, storage.ErrURLIsDeleted)
+	job, err := svc.GetDeletionJob(context.TODO(), 1, jobID)
+	require.NoError(t, err)
+	require.Equal(t, DeletionApplied, job.Status)
+	stats, err := repo.GetClickStats(context.TODO(), 1, "kept")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Total)
+
+	_, err = svc.DeleteUserURLs(context.TODO(), 1, "kept")
+	require.ErrorIs(t, err, ErrShuttingDown)
+}
+
+func TestDeletionQueueIsReplayed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(context.TODO(), storage.StoredURL{ShortID: "crash", OriginalURL: "https://crash.example/", UserID: 1}))
+	// A deletion accepted right before a crash: persisted, but never applied.
+	require.NoError(t, repo.PushDeletions(context.TODO(), storage.URLForDelete{ShortID: "crash", UserID: 1}))
+
+	reopened, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	ctx, stop := context.WithCancel(context.Background())
+	svc := NewURLService(NewShortGenerator(), "localhost", reopened, WithContext(ctx), WithDeletionQueue(reopened))
+	stop()
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.Wait(waitCtx))
+
+	_, err = reopened.Get(context.TODO(), "crash")
+	require.ErrorIs(t, err, storage.ErrURLIsDeleted)
+	pending, err := reopened.PendingDeletions(context.TODO())
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestDeletionJobOutcomes(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo, WithContext(ctx))
+	_, err := svc.Shorten(context.TODO(), "https://mine.example/", 1, ShortenOptions{Alias: "mine"})
+	require.NoError(t, err)
+	_, err = svc.Shorten(context.TODO(), "https://theirs.example/", 2, ShortenOptions{Alias: "theirs"})
+	require.NoError(t, err)
+
+	applied, err := svc.DeleteUserURLs(context.TODO(), 1, "mine")
+	require.NoError(t, err)
+	partial, err := svc.DeleteUserURLs(context.TODO(), 1, "theirs", "missing")
+	require.NoError(t, err)
+	job, err := svc.GetDeletionJob(context.TODO(), 1, applied)
+	require.NoError(t, err)
+	require.Equal(t, DeletionQueued, job.Status)
+	require.True(t, job.AppliedAt.IsZero())
+	_, err = svc.GetDeletionJob(context.TODO(), 2, applied)
+	require.ErrorIs(t, err, ErrDeletionJobNotFound)
+
+	stop()
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.Wait(waitCtx))
+
+	job, err = svc.GetDeletionJob(context.TODO(), 1, applied)
+	require.NoError(t, err)
+	require.Equal(t, DeletionApplied, job.Status)
+	require.False(t, job.AppliedAt.IsZero())
+	require.Equal(t, []DeletionItem{{ShortID: "mine", Outcome: storage.OutcomeDeleted}}, job.Items)
+
+	job, err = svc.GetDeletionJob(context.TODO(), 1, partial)
+	require.NoError(t, err)
+	require.Equal(t, DeletionPartiallyApplied, job.Status)
+	require.Equal(t, []DeletionItem{
+		{ShortID: "theirs", Outcome: storage.OutcomeNotOwned},
+		{ShortID: "missing", Outcome: storage.OutcomeNotFound},
+	}, job.Items)
+}
+
+func TestDeleteUserURLsBackpressure(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository(),
+		WithContext(ctx), WithDeletionBuffer(3), WithDeletionFlushInterval(time.Hour))
+
+	_, err := svc.DeleteUserURLs(context.TODO(), 1, "a", "b", "c", "d")
+	require.ErrorIs(t, err, ErrTooManyDeletions)
+	_, err = svc.DeleteUserURLs(context.TODO(), 1, "a", "b")
+	require.NoError(t, err)
+	_, err = svc.DeleteUserURLs(context.TODO(), 1, "c", "d")
+	var fullErr *DeletionQueueFullError
+	require.ErrorAs(t, err, &fullErr)
+	require.Equal(t, time.Hour, fullErr.RetryAfter)
+	_, err = svc.DeleteUserURLs(context.TODO(), 1, "c")
+	require.NoError(t, err, "rejected requests must free their room in the buffer")
+}
+
+func TestDeleteUserURLsFlushesFullBatch(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo,
+		WithContext(ctx), WithDeletionFlushInterval(time.Hour), WithDeletionBatchSize(2))
+	for _, alias := range []string{"one", "two", "three"} {
+		_, err := svc.Shorten(context.TODO(), "https://"+alias+".example/", 1, ShortenOptions{Alias: alias})
+		require.NoError(t, err)
+	}
+
+	first, err := svc.DeleteUserURLs(context.TODO(), 1, "one")
+	require.NoError(t, err)
+	second, err := svc.DeleteUserURLs(context.TODO(), 1, "two", "three")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err := svc.GetDeletionJob(context.TODO(), 1, second)
+		return err == nil && job.Status == DeletionApplied
+	}, time.Second, 10*time.Millisecond)
+	job, err := svc.GetDeletionJob(context.TODO(), 1, first)
+	require.NoError(t, err)
+	require.Equal(t, DeletionApplied, job.Status)
+	trash, err := repo.GetDeletedUserURLs(context.TODO(), 1)
+	require.NoError(t, err)
+	require.Len(t, trash, 3)
+}
+
+func TestNonPositiveIntervalsKeepDefaults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository(),
+		WithContext(ctx),
+		WithExpirySweepInterval(-time.Second),
+		WithPurgeRetention(time.Hour),
+		WithPurgeInterval(0),
+		WithClickFlushInterval(-time.Minute),
+	)
+	require.Equal(t, defaultExpirySweepInterval, svc.expirySweep)
+	require.Equal(t, defaultPurgeInterval, svc.purgeInterval)
+	require.Equal(t, defaultClickFlush, svc.clickFlush)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+)
+
+// Page size limits of the user URL list.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// MaxSearchLength limits the length of a search over the user URLs.
+const MaxSearchLength = 256
+
+// URLPage is a page of the URLs of a user.
+// NextCursor continues the list after the page, it is empty on the last page.
+type URLPage struct {
+	URLs       []SvcURL
+	NextCursor string
+}
+
+// GetUserURLs returns a page of the URLs created by a specific user that match the query.
+// A zero limit means DefaultPageSize; the query continues after the cursor, see DecodeCursor.
+func (s *URLService) GetUserURLs(ctx context.Context, id int64, query storage.URLQuery) (URLPage, error) {
+	query, err := normalizeURLQuery(query)
+	if err != nil {
+		return URLPage{}, err
+	}
+	limit := query.Limit
+	query.Limit++
+	storedURLs, err := s.repository.GetUserURLs(ctx, id, query)
+	if err != nil {
+		return URLPage{}, err
+	}
+	var page URLPage
+	if len(storedURLs) > limit {
+		storedURLs = storedURLs[:limit]
+		page.NextCursor = EncodeCursor(query, storedURLs[limit-1].Cursor())
+	}
+	for _, stored := range storedURLs {
+		page.URLs = append(page.URLs, s.svcURL(stored))
+	}
+	return page, nil
+}
+
+// normalizeURLQuery checks the query and brings its tag, search, domain and limit to the form used by the repository.
+func normalizeURLQuery(query storage.URLQuery) (storage.URLQuery, error) {
+	var err error
+	if query.Tag != "" {
+		if query.Tag, err = normalizeTag(query.Tag); err != nil {
+			return query, err
+		}
+	}
+	if query.Search != "" {
+		query.Search = strings.ToLower(strings.TrimSpace(query.Search))
+		if query.Search == "" || utf8.RuneCountInString(query.Search) > MaxSearchLength {
+			return query, fmt.Errorf("%w: search must be between 1 and %d characters long", ErrInvalidQuery, MaxSearchLength)
+		}
+	}
+	if query.Domain != "" {
+		query.Domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(query.Domain)), ".")
+		if query.Domain == "" || strings.ContainsAny(query.Domain, "/:?#@[] ") {
+			return query, fmt.Errorf("%w: invalid domain", ErrInvalidQuery)
+		}
+	}
+	if !query.CreatedAfter.IsZero() && !query.CreatedBefore.IsZero() && !query.CreatedAfter.Before(query.CreatedBefore) {
+		return query, fmt.Errorf("%w: created_after must be before created_before", ErrInvalidQuery)
+	}
+	switch query.Sort {
+	case "", storage.SortByCreated, storage.SortByShort:
+	default:
+		return query, fmt.Errorf("%w: unknown sort %q", ErrInvalidQuery, query.Sort)
+	}
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultPageSize
+	case query.Limit < 0 || query.Limit > MaxPageSize:
+		return query, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	return query, nil
+}
+
+// EncodeCursor returns an opaque cursor pointing at the position in the order of the query.
+// A zero creation time, that of URLs created before it was recorded, is kept as is.
+func EncodeCursor(query storage.URLQuery, pos storage.URLCursor) string {
+	var created string
+	if !pos.CreatedAt.IsZero() {
+		created = strconv.FormatInt(pos.CreatedAt.UnixNano(), 10)
+	}
+	raw := strings.Join([]string{cursorOrder(query), created, pos.ShortID}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor.
+// The cursor must come from a query with the same sort order.
+func DecodeCursor(query storage.URLQuery, cursor string) (*storage.URLCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if parts[0] != cursorOrder(query) {
+		return nil, fmt.Errorf("%w: cursor does not match the sort order", ErrInvalidQuery)
+	}
+	pos := &storage.URLCursor{ShortID: parts[2]}
+	if parts[1] != "" {
+		nanos, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+		pos.CreatedAt = time.Unix(0, nanos)
+	}
+	return pos, nil
+}
+
+// cursorOrder identifies the sort order of the query in a cursor.
+func cursorOrder(query storage.URLQuery) string {
+	order := string(query.Sort)
+	if order == "" {
+		order = string(storage.SortByCreated)
+	}
+	if query.Desc {
+		order = "-" + order
+	}
+	return order
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserURLsPages(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	for i := range 5 {
+		_, err := svc.Shorten(ctx, fmt.Sprintf("https://site%d.example/", i), 1, ShortenOptions{Alias: fmt.Sprintf("page%d", i)})
+		require.NoError(t, err)
+	}
+
+	query := storage.URLQuery{Sort: storage.SortByShort, Desc: true, Limit: 2}
+	var got []string
+	for {
+		page, err := svc.GetUserURLs(ctx, 1, query)
+		require.NoError(t, err)
+		for _, u := range page.URLs {
+			got = append(got, u.ShortURL)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.After, err = DecodeCursor(query, page.NextCursor)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{
+		"localhost/page4", "localhost/page3", "localhost/page2", "localhost/page1", "localhost/page0",
+	}, got)
+
+	page, err := svc.GetUserURLs(ctx, 1, storage.URLQuery{Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, 5)
+	require.Empty(t, page.NextCursor)
+	require.NotNil(t, page.URLs[0].CreatedAt)
+
+	page, err = svc.GetUserURLs(ctx, 1, storage.URLQuery{Domain: " Site3.Example. "})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, 1)
+}
+
+func TestGetUserURLsInvalidQuery(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	now := time.Now()
+	for _, query := range []storage.URLQuery{
+		{Limit: MaxPageSize + 1},
+		{Limit: -1},
+		{Sort: "original_url"},
+		{Domain: "example.com/path"},
+		{CreatedAfter: now, CreatedBefore: now},
+	} {
+		_, err := svc.GetUserURLs(ctx, 1, query)
+		require.ErrorIs(t, err, ErrInvalidQuery, "%+v", query)
+	}
+
+	cursor := EncodeCursor(storage.URLQuery{}, storage.URLCursor{CreatedAt: now, ShortID: "abc"})
+	after, err := DecodeCursor(storage.URLQuery{Sort: storage.SortByCreated}, cursor)
+	require.NoError(t, err)
+	require.True(t, after.CreatedAt.Equal(now))
+	require.Equal(t, "abc", after.ShortID)
+	_, err = DecodeCursor(storage.URLQuery{Desc: true}, cursor)
+	require.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = DecodeCursor(storage.URLQuery{}, "not a cursor")
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -1,9 +1,162 @@
 package service
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Limits of the free-form text of a link, in bytes.
+const (
+	MaxTitleLength = 256
+	MaxNotesLength = 4096
+)
+
 // SvcURL represents a URL record in the service layer containing both short and original URLs.
 type SvcURL struct {
-	ShortURL    string
+	ShortURL     string
+	OriginalURL  string
+	UserID       int64
+	IsDeleted    bool
+	ExpiresAt    *time.Time
+	ClicksLeft   *int64
+	RedirectType int
+	QueryPolicy  string
+	Title        string
+	Notes        string
+	Tags         []string
+	CreatedAt    *time.Time
+}
+
+// ShortenOptions holds optional settings for a link being shortened.
+type ShortenOptions struct {
+	// Alias is a custom short ID chosen by the user instead of a generated one.
+	Alias string
+	// ExpiresIn makes the link expire after the given duration. Mutually exclusive with ExpiresAt.
+	ExpiresIn time.Duration
+	// ExpiresAt makes the link expire at the given moment. Mutually exclusive with ExpiresIn.
+	ExpiresAt time.Time
+	// MaxClicks limits how many times the link can be followed, zero means unlimited.
+	MaxClicks int64
+	// Password protects the link, only those who know it are redirected.
+	Password string
+	// RedirectType is the HTTP status code used to redirect, zero means the server default.
+	RedirectType int
+	// QueryPolicy is how the query of a request is merged into the original URL, empty means QueryIgnore.
+	QueryPolicy string
+	// Title and Notes are free-form text of the owner, see ValidateTitle and ValidateNotes.
+	Title string
+	Notes string
+	// Tags are free-form labels of the link, see NormalizeTags.
+	Tags []string
+}
+
+// clicksLeft resolves the click limit into the initial number of clicks, nil means unlimited.
+func (o ShortenOptions) clicksLeft() (*int64, error) {
+	if o.MaxClicks < 0 {
+		return nil, fmt.Errorf("%w: max_clicks must be positive", ErrInvalidMaxClicks)
+	}
+	if o.MaxClicks == 0 {
+		return nil, nil
+	}
+	left := o.MaxClicks
+	return &left, nil
+}
+
+// apply validates the options and sets the corresponding fields of the URL record.
+func (o ShortenOptions) apply(url *storage.StoredURL, now time.Time) error {
+	var err error
+	if url.ExpiresAt, err = o.expiry(now); err != nil {
+		return err
+	}
+	if url.ClicksLeft, err = o.clicksLeft(); err != nil {
+		return err
+	}
+	if url.PasswordHash, err = o.passwordHash(); err != nil {
+		return err
+	}
+	if o.RedirectType != 0 {
+		if err = ValidateRedirectType(o.RedirectType); err != nil {
+			return err
+		}
+	}
+	url.RedirectType = o.RedirectType
+	if o.QueryPolicy != "" {
+		if err = ValidateQueryPolicy(o.QueryPolicy); err != nil {
+			return err
+		}
+	}
+	url.QueryPolicy = o.QueryPolicy
+	if url.Title, err = ValidateTitle(o.Title); err != nil {
+		return err
+	}
+	if err = ValidateNotes(o.Notes); err != nil {
+		return err
+	}
+	url.Notes = o.Notes
+	url.Tags, err = NormalizeTags(o.Tags)
+	return err
+}
+
+// expiry resolves the expiry options into an absolute expiry time, nil means the link never expires.
+func (o ShortenOptions) expiry(now time.Time) (*time.Time, error) {
+	switch {
+	case o.ExpiresIn != 0 && !o.ExpiresAt.IsZero():
+		return nil, fmt.Errorf("%w: expires_in and expires_at are mutually exclusive", ErrInvalidExpiry)
+	case o.ExpiresIn < 0:
+		return nil, fmt.Errorf("%w: expires_in must be positive", ErrInvalidExpiry)
+	case o.ExpiresIn > 0:
+		expiresAt := now.Add(o.ExpiresIn)
+		return &expiresAt, nil
+	case !o.ExpiresAt.IsZero():
+		if !o.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("%w: expires_at is in the past", ErrInvalidExpiry)
+		}
+		expiresAt := o.ExpiresAt
+		return &expiresAt, nil
+	}
+	return nil, nil
+}
+
+// BatchItem represents a single URL of a batch shortening request.
+type BatchItem struct {
 	OriginalURL string
-	UserID      int64
-	IsDeleted   bool
+	ShortenOptions
+}
+
+// passwordHash hashes the link password, an empty hash means the link is public.
+func (o ShortenOptions) passwordHash() (string, error) {
+	if o.Password == "" {
+		return "", nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(o.Password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("%w: password must be at most 72 bytes", ErrInvalidPassword)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// ValidateTitle returns the title without surrounding spaces if it is valid UTF-8 within MaxTitleLength.
+func ValidateTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if len(title) > MaxTitleLength || !utf8.ValidString(title) {
+		return "", fmt.Errorf("%w: must be valid UTF-8 of at most %d bytes", ErrInvalidTitle, MaxTitleLength)
+	}
+	return title, nil
+}
+
+// ValidateNotes checks that the notes are valid UTF-8 within MaxNotesLength.
+func ValidateNotes(notes string) error {
+	if len(notes) > MaxNotesLength || !utf8.ValidString(notes) {
+		return fmt.Errorf("%w: must be valid UTF-8 of at most %d bytes", ErrInvalidNotes, MaxNotesLength)
+	}
+	return nil
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultMaxURLLength is the default limit of an original URL length in bytes.
+const DefaultMaxURLLength = 2048
+
+// DefaultAllowedSchemes lists the schemes accepted when none are configured.
+var DefaultAllowedSchemes = []string{"http", "https"}
+
+// defaultPorts maps schemes to ports that are implied and therefore stripped.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+}
+
+// URLNormalizer validates original URLs and brings them to a canonical form,
+// so that equivalent spellings of a URL are deduplicated to the same short link.
+type URLNormalizer struct {
+	schemes       []string
+	maxLength     int
+	stripFragment bool
+}
+
+// NewURLNormalizer creates a new URLNormalizer.
+// Empty schemes mean DefaultAllowedSchemes and a non-positive maxLength means DefaultMaxURLLength.
+func NewURLNormalizer(schemes []string, maxLength int, stripFragment bool) *URLNormalizer {
+	if len(schemes) == 0 {
+		schemes = DefaultAllowedSchemes
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxURLLength
+	}
+	lower := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		lower[i] = strings.ToLower(strings.TrimSpace(scheme))
+	}
+	return &URLNormalizer{schemes: lower, maxLength: maxLength, stripFragment: stripFragment}
+}
+
+// Normalize validates the raw URL and returns its canonical form.
+// The scheme must be allowed and the host present. The scheme and host are lowercased,
+// IDN hosts are converted to punycode, default ports are stripped, and so is the fragment if configured.
+// It returns an error wrapping ErrInvalidURL if the URL is rejected.
+func (n *URLNormalizer) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > n.maxLength {
+		return "", fmt.Errorf("%w: longer than %d bytes", ErrInvalidURL, n.maxLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !slices.Contains(n.schemes, u.Scheme) {
+		return "", fmt.Errorf("%w: scheme %q is not allowed", ErrInvalidURL, u.Scheme)
+	}
+	if u.Opaque != "" || u.Hostname() == "" {
+		return "", fmt.Errorf("%w: host is required", ErrInvalidURL)
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	if n.stripFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	normalized := u.String()
+	if len(normalized) > n.maxLength {
+		return "", fmt.Errorf("%w: longer than %d bytes", ErrInvalidURL, n.maxLength)
+	}
+	return normalized, nil
+}
+
+// normalizeHost lowercases an IP literal or converts a domain name to its ASCII form.
+func normalizeHost(host string) (string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return strings.ToLower(host), nil
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad host %q: %v", ErrInvalidURL, host, err)
+	}
+	return ascii, nil
+}
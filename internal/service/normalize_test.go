@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLNormalizer(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		stripFragment bool
+		want          string
+		wantErr       bool
+	}{
+		{name: "plain", raw: "https://example.com/path?q=1", want: "https://example.com/path?q=1"},
+		{name: "case", raw: "HTTP://Example.COM/Path", want: "http://example.com/Path"},
+		{name: "default_http_port", raw: "http://example.com:80/", want: "http://example.com/"},
+		{name: "default_https_port", raw: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "custom_port", raw: "http://example.com:8080/", want: "http://example.com:8080/"},
+		{name: "idn", raw: "https://пример.рф/путь", want: "https://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C"},
+		{name: "ipv6", raw: "http://[2001:DB8::1]:80/", want: "http://[2001:db8::1]/"},
+		{name: "ipv6_port", raw: "http://[2001:db8::1]:8080/", want: "http://[2001:db8::1]:8080/"},
+		{name: "keeps_fragment", raw: "https://example.com/#top", want: "https://example.com/#top"},
+		{name: "strips_fragment", raw: "https://example.com/#top", stripFragment: true, want: "https://example.com/"},
+		{name: "trims_spaces", raw: "  https://example.com/ \n", want: "https://example.com/"},
+		{name: "javascript", raw: "javascript:alert(1)", wantErr: true},
+		{name: "ftp_not_allowed", raw: "ftp://example.com/file", wantErr: true},
+		{name: "no_scheme", raw: "example.com", wantErr: true},
+		{name: "garbage", raw: "just some text", wantErr: true},
+		{name: "no_host", raw: "https:///path", wantErr: true},
+		{name: "bad_idn", raw: "https://exa mple.com/", wantErr: true},
+		{name: "too_long", raw: "https://example.com/" + strings.Repeat("a", DefaultMaxURLLength), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewURLNormalizer(nil, 0, tt.stripFragment).Normalize(tt.raw)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidURL)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestURLNormalizerCustomSchemes(t *testing.T) {
+	n := NewURLNormalizer([]string{"HTTPS", "ftp"}, 64, false)
+	got, err := n.Normalize("ftp://example.com:21/file")
+	require.NoError(t, err)
+	require.Equal(t, "ftp://example.com/file", got)
+
+	_, err = n.Normalize("http://example.com/")
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = n.Normalize("https://example.com/" + strings.Repeat("a", 64))
+	require.ErrorIs(t, err, ErrInvalidURL)
+}
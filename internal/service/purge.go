@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"go.uber.org/zap"
+)
+
+const defaultPurgeInterval = time.Hour
+
+// PurgeStats describes the hard purges of deleted and expired links since the service started.
+// Retention is zero if purging is disabled; LastRunAt is zero until the first purge.
+type PurgeStats struct {
+	Retention time.Duration
+	LastRunAt time.Time
+	Last      storage.PurgeCounts
+	Total     storage.PurgeCounts
+}
+
+// purgeState holds the purge statistics guarded by a mutex.
+type purgeState struct {
+	mu    sync.Mutex
+	stats PurgeStats
+}
+
+// WithPurgeRetention enables the hard purge of links deleted or expired longer than d ago.
+// Deleted links are kept at least as long as they can be restored from the trash.
+func WithPurgeRetention(d time.Duration) Option {
+	return func(s *URLService) {
+		s.purgeRetention = d
+	}
+}
+
+// WithPurgeInterval sets how often the hard purge runs.
+// Non-positive intervals are ignored.
+func WithPurgeInterval(d time.Duration) Option {
+	return func(s *URLService) {
+		if d > 0 {
+			s.purgeInterval = d
+		}
+	}
+}
+
+// Purge physically removes the links deleted or expired longer than the purge retention ago
+// and returns how many were removed.
+func (s *URLService) Purge(ctx context.Context) (storage.PurgeCounts, error) {
+	now := time.Now()
+	expiredBefore := now.Add(-s.purgeRetention)
+	deletedBefore := now.Add(-max(s.purgeRetention, s.trashRetention))
+	counts, err := s.repository.PurgeURLs(ctx, deletedBefore, expiredBefore)
+
+	s.purge.mu.Lock()
+	defer s.purge.mu.Unlock()
+	s.purge.stats.LastRunAt = now
+	s.purge.stats.Last = counts
+	s.purge.stats.Total.Deleted += counts.Deleted
+	s.purge.stats.Total.Expired += counts.Expired
+	return counts, err
+}
+
+// PurgeStats returns the statistics of the hard purges.
+func (s *URLService) PurgeStats() PurgeStats {
+	s.purge.mu.Lock()
+	defer s.purge.mu.Unlock()
+	stats := s.purge.stats
+	stats.Retention = s.purgeRetention
+	return stats
+}
+
+// purgeJob periodically purges old deleted and expired links until the service context is canceled.
+func (s *URLService) purgeJob() {
+	ticker := time.NewTicker(s.purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+		counts, err := s.Purge(context.Background())
+		if err != nil {
+			s.log.Error("failed to purge urls", zap.Error(err))
+		}
+		if counts.Deleted+counts.Expired > 0 {
+			s.log.Info("purged urls", zap.Int64("deleted", counts.Deleted), zap.Int64("expired", counts.Expired))
+		}
+	}
+}
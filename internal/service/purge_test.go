@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeKeepsRestorableLinks(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo,
+		WithPurgeRetention(time.Nanosecond), WithPurgeInterval(time.Hour), WithTrashRetention(time.Hour))
+	for _, alias := range []string{"gone", "soon"} {
+		_, err := svc.Shorten(ctx, "https://"+alias+".example/", 1, ShortenOptions{Alias: alias})
+		require.NoError(t, err)
+	}
+	_, err := svc.Shorten(ctx, "https://brief.example/", 1, ShortenOptions{Alias: "brief", ExpiresIn: time.Millisecond})
+	require.NoError(t, err)
+	repo.MarkDeletedUserURLs(ctx, storage.URLForDelete{ShortID: "gone", UserID: 1})
+	time.Sleep(5 * time.Millisecond)
+
+	counts, err := svc.Purge(ctx)
+	require.NoError(t, err)
+	require.Equal(t, storage.PurgeCounts{Expired: 1}, counts)
+	trash, err := svc.GetTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, trash, 1, "deleted link must stay restorable for the trash retention")
+
+	stats := svc.PurgeStats()
+	require.Equal(t, time.Nanosecond, stats.Retention)
+	require.False(t, stats.LastRunAt.IsZero())
+	require.Equal(t, counts, stats.Last)
+	require.Equal(t, counts, stats.Total)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+)
+
+// permanentRedirectMaxAge is how long clients may cache a permanent redirect.
+const permanentRedirectMaxAge = 24 * time.Hour
+
+// redirectTypes lists the HTTP status codes a link may redirect with.
+var redirectTypes = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+}
+
+// Redirect describes how to send the client to the original URL of a link.
+// A zero MaxAge means the redirect must not be cached.
+type Redirect struct {
+	URL        string
+	StatusCode int
+	MaxAge     time.Duration
+}
+
+// ValidateRedirectType checks that code is one of 301, 302, 307 and 308.
+func ValidateRedirectType(code int) error {
+	if !slices.Contains(redirectTypes, code) {
+		return fmt.Errorf("%w: %d is not one of %v", ErrInvalidRedirectType, code, redirectTypes)
+	}
+	return nil
+}
+
+// WithDefaultRedirectType sets the redirect status code of links created without redirect_type.
+func WithDefaultRedirectType(code int) Option {
+	return func(s *URLService) {
+		s.defaultRedirect = code
+	}
+}
+
+// isPermanent reports whether clients may cache the redirect.
+func isPermanent(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// redirect builds the redirect of a stored URL at now.
+// Permanent redirects are cached for at most permanentRedirectMaxAge and never past the link expiry,
+// while click-limited links are never cached so that every follow is counted.
+func (s *URLService) redirect(stored storage.StoredURL, now time.Time) Redirect {
+	r := Redirect{URL: stored.OriginalURL, StatusCode: stored.RedirectType}
+	if r.StatusCode == 0 {
+		r.StatusCode = s.defaultRedirect
+	}
+	if !isPermanent(r.StatusCode) || stored.ClicksLeft != nil {
+		return r
+	}
+	r.MaxAge = permanentRedirectMaxAge
+	if stored.ExpiresAt != nil {
+		r.MaxAge = max(0, min(r.MaxAge, stored.ExpiresAt.Sub(now).Truncate(time.Second)))
+	}
+	return r
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectType(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository(),
+		WithDefaultRedirectType(http.StatusFound))
+
+	_, err := svc.Shorten(ctx, "https://bad.example/", 1, ShortenOptions{Alias: "bad", RedirectType: http.StatusOK})
+	require.ErrorIs(t, err, ErrInvalidRedirectType)
+
+	_, err = svc.Shorten(ctx, "https://default.example/", 1, ShortenOptions{Alias: "default"})
+	require.NoError(t, err)
+	redirect, err := svc.GetOriginal(ctx, "default", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusFound, redirect.StatusCode)
+	require.Zero(t, redirect.MaxAge)
+
+	_, err = svc.Shorten(ctx, "https://permanent.example/", 1, ShortenOptions{Alias: "permanent", RedirectType: http.StatusPermanentRedirect})
+	require.NoError(t, err)
+	redirect, err = svc.GetOriginal(ctx, "permanent", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusPermanentRedirect, redirect.StatusCode)
+	require.Equal(t, permanentRedirectMaxAge, redirect.MaxAge)
+}
+
+func TestRedirectCacheLifetime(t *testing.T) {
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	now := time.Now()
+	soon := now.Add(90 * time.Second)
+	clicks := int64(3)
+
+	r := svc.redirect(storage.StoredURL{RedirectType: http.StatusMovedPermanently, ExpiresAt: &soon}, now)
+	require.Equal(t, 90*time.Second, r.MaxAge)
+
+	r = svc.redirect(storage.StoredURL{RedirectType: http.StatusMovedPermanently, ClicksLeft: &clicks}, now)
+	require.Zero(t, r.MaxAge)
+
+	r = svc.redirect(storage.StoredURL{RedirectType: http.StatusTemporaryRedirect}, now)
+	require.Zero(t, r.MaxAge)
+}
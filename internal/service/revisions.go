@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+)
+
+// LinkUpdate holds the changes of an existing link; zero fields are left as is.
+// ExpiresIn and ExpiresAt set a new expiry as in ShortenOptions, NoExpiry removes it.
+// A RedirectType pointing to zero resets the link to the server default,
+// a QueryPolicy pointing to an empty string resets it to QueryIgnore.
+// Title and Notes pointing to empty strings clear them.
+type LinkUpdate struct {
+	OriginalURL  *string
+	RedirectType *int
+	QueryPolicy  *string
+	Title        *string
+	Notes        *string
+	ExpiresIn    time.Duration
+	ExpiresAt    time.Time
+	NoExpiry     bool
+}
+
+func (u LinkUpdate) changesExpiry() bool {
+	return u.NoExpiry || u.ExpiresIn != 0 || !u.ExpiresAt.IsZero()
+}
+
+// UpdateURL changes the original URL, redirect type, query policy, expiry, title or notes of a link owned by the user.
+// Every change is kept as a revision.
+func (s *URLService) UpdateURL(ctx context.Context, userID int64, shortID string, upd LinkUpdate) (SvcURL, error) {
+	if upd.OriginalURL == nil && upd.RedirectType == nil && upd.QueryPolicy == nil && !upd.changesExpiry() &&
+		upd.Title == nil && upd.Notes == nil {
+		return SvcURL{}, ErrEmptyUpdate
+	}
+	var original string
+	if upd.OriginalURL != nil {
+		var err error
+		if original, err = s.normalizer.Normalize(*upd.OriginalURL); err != nil {
+			return SvcURL{}, err
+		}
+		if err := s.checkBlocked(original, "update"); err != nil {
+			return SvcURL{}, err
+		}
+	}
+	if upd.RedirectType != nil && *upd.RedirectType != 0 {
+		if err := ValidateRedirectType(*upd.RedirectType); err != nil {
+			return SvcURL{}, err
+		}
+	}
+	if upd.QueryPolicy != nil && *upd.QueryPolicy != "" {
+		if err := ValidateQueryPolicy(*upd.QueryPolicy); err != nil {
+			return SvcURL{}, err
+		}
+	}
+	var title string
+	if upd.Title != nil {
+		var err error
+		if title, err = ValidateTitle(*upd.Title); err != nil {
+			return SvcURL{}, err
+		}
+	}
+	if upd.Notes != nil {
+		if err := ValidateNotes(*upd.Notes); err != nil {
+			return SvcURL{}, err
+		}
+	}
+	if upd.NoExpiry && (upd.ExpiresIn != 0 || !upd.ExpiresAt.IsZero()) {
+		return SvcURL{}, fmt.Errorf("%w: no_expiry excludes expires_in and expires_at", ErrInvalidExpiry)
+	}
+	expiresAt, err := ShortenOptions{ExpiresIn: upd.ExpiresIn, ExpiresAt: upd.ExpiresAt}.expiry(time.Now())
+	if err != nil {
+		return SvcURL{}, err
+	}
+
+	return s.updateURL(ctx, userID, shortID, func(url *storage.StoredURL) error {
+		if upd.OriginalURL != nil {
+			url.OriginalURL = original
+		}
+		if upd.RedirectType != nil {
+			url.RedirectType = *upd.RedirectType
+		}
+		if upd.QueryPolicy != nil {
+			url.QueryPolicy = *upd.QueryPolicy
+		}
+		if upd.Title != nil {
+			url.Title = title
+		}
+		if upd.Notes != nil {
+			url.Notes = *upd.Notes
+		}
+		if upd.changesExpiry() {
+			url.ExpiresAt = expiresAt
+			url.IsExpired = false
+		}
+		return nil
+	})
+}
+
+// GetHistory returns the revisions of a link owned by the user, oldest first.
+func (s *URLService) GetHistory(ctx context.Context, userID int64, shortID string) ([]storage.Revision, error) {
+	return s.repository.GetRevisions(ctx, userID, shortID)
+}
+
+// Rollback restores the original URL, redirect type, query policy, expiry, title and notes of a link owned by the user
+// from one of its revisions. The rollback itself is recorded as a new revision.
+// Rolling back to a revision whose expiry has passed returns ErrInvalidExpiry, so that the link
+// does not expire as a side effect.
+func (s *URLService) Rollback(ctx context.Context, userID int64, shortID string, revision int64) (SvcURL, error) {
+	revisions, err := s.repository.GetRevisions(ctx, userID, shortID)
+	if err != nil {
+		return SvcURL{}, err
+	}
+	var target *storage.Revision
+	for i := range revisions {
+		if revisions[i].Number == revision {
+			target = &revisions[i]
+		}
+	}
+	if target == nil {
+		return SvcURL{}, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	if target.ExpiresAt != nil && !target.ExpiresAt.After(time.Now()) {
+		return SvcURL{}, fmt.Errorf("%w: revision %d expired at %s", ErrInvalidExpiry, revision, target.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if err := s.checkBlocked(target.OriginalURL, "rollback"); err != nil {
+		return SvcURL{}, err
+	}
+	return s.updateURL(ctx, userID, shortID, func(url *storage.StoredURL) error {
+		url.OriginalURL = target.OriginalURL
+		url.RedirectType = target.RedirectType
+		url.QueryPolicy = target.QueryPolicy
+		url.ExpiresAt = target.ExpiresAt
+		url.Title = target.Title
+		url.Notes = target.Notes
+		url.IsExpired = false
+		return nil
+	})
+}
+
+func (s *URLService) updateURL(ctx context.Context, userID int64, shortID string, apply func(*storage.StoredURL) error) (SvcURL, error) {
+	updated, err := s.repository.UpdateURL(ctx, userID, shortID, apply)
+	var existErr *storage.ErrOriginalExist
+	if errors.As(err, &existErr) {
+		return SvcURL{}, NewOriginalExistError(s.addBaseURL(existErr.Short))
+	}
+	if err != nil {
+		return SvcURL{}, err
+	}
+	return s.svcURL(updated), nil
+}
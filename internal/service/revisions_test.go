@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateURLAndRollback(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	_, err := svc.Shorten(ctx, "https://v1.example/", 1, ShortenOptions{Alias: "edit"})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateURL(ctx, 1, "edit", LinkUpdate{})
+	require.ErrorIs(t, err, ErrEmptyUpdate)
+	bad := "javascript:alert(1)"
+	_, err = svc.UpdateURL(ctx, 1, "edit", LinkUpdate{OriginalURL: &bad})
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = svc.UpdateURL(ctx, 1, "edit", LinkUpdate{NoExpiry: true, ExpiresIn: time.Hour})
+	require.ErrorIs(t, err, ErrInvalidExpiry)
+
+	v2 := "HTTPS://V2.example/"
+	permanent := http.StatusMovedPermanently
+	updated, err := svc.UpdateURL(ctx, 1, "edit", LinkUpdate{OriginalURL: &v2, RedirectType: &permanent, ExpiresIn: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, "https://v2.example/", updated.OriginalURL)
+	require.Equal(t, http.StatusMovedPermanently, updated.RedirectType)
+	require.NotNil(t, updated.ExpiresAt)
+
+	redirect, err := svc.GetOriginal(ctx, "edit", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, "https://v2.example/", redirect.URL)
+	require.Equal(t, http.StatusMovedPermanently, redirect.StatusCode)
+
+	_, err = svc.UpdateURL(ctx, 2, "edit", LinkUpdate{OriginalURL: &v2})
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	_, err = svc.Rollback(ctx, 1, "edit", 5)
+	require.ErrorIs(t, err, ErrRevisionNotFound)
+	restored, err := svc.Rollback(ctx, 1, "edit", 1)
+	require.NoError(t, err)
+	require.Equal(t, "https://v1.example/", restored.OriginalURL)
+	require.Zero(t, restored.RedirectType)
+	require.Nil(t, restored.ExpiresAt)
+
+	history, err := svc.GetHistory(ctx, 1, "edit")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, "https://v1.example/", history[2].OriginalURL)
+}
+
+func TestTitleAndNotesRevisions(t *testing.T) {
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	svc := NewURLService(NewShortGenerator(), "localhost", repo)
+	_, err = svc.Shorten(ctx, "https://v1.example/", 1, ShortenOptions{Alias: "meta", Title: "Launch", Notes: "draft"})
+	require.NoError(t, err)
+	title, notes := "Launch v2", ""
+	_, err = svc.UpdateURL(ctx, 1, "meta", LinkUpdate{Title: &title, Notes: &notes})
+	require.NoError(t, err)
+
+	reopened, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	svc = NewURLService(NewShortGenerator(), "localhost", reopened)
+	history, err := svc.GetHistory(ctx, 1, "meta")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, "Launch", history[0].Title)
+	require.Equal(t, "draft", history[0].Notes)
+	require.Equal(t, "Launch v2", history[1].Title)
+	require.Empty(t, history[1].Notes)
+
+	restored, err := svc.Rollback(ctx, 1, "meta", 1)
+	require.NoError(t, err)
+	require.Equal(t, "Launch", restored.Title)
+	require.Equal(t, "draft", restored.Notes)
+}
+
+func TestRollbackToExpiredRevision(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	_, err := svc.Shorten(ctx, "https://v1.example/", 1, ShortenOptions{Alias: "soon", ExpiresAt: time.Now().Add(50 * time.Millisecond)})
+	require.NoError(t, err)
+	_, err = svc.UpdateURL(ctx, 1, "soon", LinkUpdate{NoExpiry: true})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = svc.Rollback(ctx, 1, "soon", 1)
+	require.ErrorIs(t, err, ErrInvalidExpiry)
+	_, err = svc.GetOriginal(ctx, "soon", TargetParams{})
+	require.NoError(t, err)
+	history, err := svc.GetHistory(ctx, 1, "soon")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+)
+
+// Tag limits.
+const (
+	MaxTagLength   = 64
+	MaxTagsPerLink = 32
+)
+
+// NormalizeTags checks free-form tags and brings them to their stored form:
+// trimmed, lowercase, unique and sorted. A tag must be 1 to MaxTagLength characters long and
+// must not contain control characters or commas; a link carries at most MaxTagsPerLink tags.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxTagsPerLink {
+		return nil, fmt.Errorf("%w: at most %d tags per link", ErrInvalidTag, MaxTagsPerLink)
+	}
+	return normalized, nil
+}
+
+// normalizeTag checks a single tag and returns its stored form.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", fmt.Errorf("%w: length must be between 1 and %d", ErrInvalidTag, MaxTagLength)
+	}
+	if i := strings.IndexFunc(tag, func(c rune) bool { return c == ',' || unicode.IsControl(c) }); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(tag[i:])
+		return "", fmt.Errorf("%w: character %q is not allowed", ErrInvalidTag, r)
+	}
+	return tag, nil
+}
+
+// SetTags replaces the tags of a link owned by the user.
+func (s *URLService) SetTags(ctx context.Context, userID int64, shortID string, tags []string) (SvcURL, error) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return SvcURL{}, err
+	}
+	stored, err := s.repository.SetTags(ctx, userID, shortID, tags)
+	if err != nil {
+		return SvcURL{}, err
+	}
+	return s.svcURL(stored), nil
+}
+
+// GetUserTags returns the tags of the user's links with how many links carry them, most used first.
+func (s *URLService) GetUserTags(ctx context.Context, userID int64) ([]storage.TagCount, error) {
+	return s.repository.GetUserTags(ctx, userID)
+}
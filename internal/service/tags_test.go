@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Work ", "news", "work", "Новости"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"news", "work", "новости"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	for _, bad := range []string{"", "  ", "a,b", "tab\there", strings.Repeat("x", MaxTagLength+1)} {
+		_, err = NormalizeTags([]string{bad})
+		require.ErrorIs(t, err, ErrInvalidTag, bad)
+	}
+	tooMany := make([]string, MaxTagsPerLink+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("t", i+1)
+	}
+	_, err = NormalizeTags(tooMany)
+	require.ErrorIs(t, err, ErrInvalidTag)
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	_, err := svc.Shorten(ctx, "https://a.example/", 1, ShortenOptions{Alias: "tagged", Tags: []string{"Work"}})
+	require.NoError(t, err)
+	_, err = svc.Shorten(ctx, "https://b.example/", 1, ShortenOptions{Alias: "plain"})
+	require.NoError(t, err)
+	_, err = svc.Shorten(ctx, "https://c.example/", 1, ShortenOptions{Tags: []string{"a,b"}})
+	require.ErrorIs(t, err, ErrInvalidTag)
+
+	page, err := svc.GetUserURLs(ctx, 1, storage.URLQuery{Tag: " WORK "})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, 1)
+	require.Equal(t, []string{"work"}, page.URLs[0].Tags)
+
+	updated, err := svc.SetTags(ctx, 1, "plain", []string{"work", "Personal"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"personal", "work"}, updated.Tags)
+	_, err = svc.SetTags(ctx, 2, "plain", []string{"work"})
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	tags, err := svc.GetUserTags(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []storage.TagCount{{Tag: "work", Count: 2}, {Tag: "personal", Count: 1}}, tags)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Query policies of a link, see TargetParams.
+const (
+	// QueryIgnore drops the query of the request, it is the policy of links created without one.
+	QueryIgnore = "ignore"
+	// QueryPassthrough adds the query parameters of the request that the original URL does not have.
+	QueryPassthrough = "passthrough"
+	// QueryOverride adds the query parameters of the request, replacing those of the original URL.
+	QueryOverride = "override"
+)
+
+var queryPolicies = []string{QueryIgnore, QueryPassthrough, QueryOverride}
+
+// placeholder matches a {name} placeholder of an original URL, also in its percent-encoded form
+// as the path is stored after normalization.
+var placeholder = regexp.MustCompile(`(?:\{|%7[Bb])([A-Za-z0-9_]+)(?:\}|%7[Dd])`)
+
+// TargetParams holds the parts of a request for a short link that are merged into the original URL.
+//
+// Placeholders such as {sku} in the original URL are filled first from Segments, in the order
+// the placeholders appear, and then from the query parameters of the same name. Every placeholder
+// must get a non-empty value and every segment must be used. The query parameters left over are
+// merged according to the query policy of the link.
+type TargetParams struct {
+	Query    url.Values
+	Segments []string
+}
+
+// ValidateQueryPolicy checks that policy is one of ignore, passthrough and override.
+func ValidateQueryPolicy(policy string) error {
+	if !slices.Contains(queryPolicies, policy) {
+		return fmt.Errorf("%w: %q is not one of %v", ErrInvalidQueryPolicy, policy, queryPolicies)
+	}
+	return nil
+}
+
+// target builds the URL a link with the original URL and query policy redirects to for the request params.
+// Placeholders can not change the scheme or host of the URL.
+func target(original, policy string, params TargetParams) (string, error) {
+	query := url.Values{}
+	for key, values := range params.Query {
+		query[key] = values
+	}
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(original, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	if len(params.Segments) > len(names) {
+		return "", fmt.Errorf("%w: unexpected path segments", ErrInvalidTarget)
+	}
+	values := make(map[string]string, len(names))
+	for i, name := range names {
+		if i < len(params.Segments) {
+			values[name] = params.Segments[i]
+		} else {
+			values[name] = query.Get(name)
+		}
+		query.Del(name)
+		if values[name] == "" {
+			return "", fmt.Errorf("%w: missing value of {%s}", ErrInvalidTarget, name)
+		}
+	}
+
+	result := original
+	if len(names) > 0 {
+		queryStart := strings.IndexAny(original, "?#")
+		if queryStart < 0 {
+			queryStart = len(original)
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range placeholder.FindAllStringSubmatchIndex(original, -1) {
+			b.WriteString(original[last:loc[0]])
+			value := values[original[loc[2]:loc[3]]]
+			if loc[0] < queryStart {
+				b.WriteString(url.PathEscape(value))
+			} else {
+				b.WriteString(url.QueryEscape(value))
+			}
+			last = loc[1]
+		}
+		b.WriteString(original[last:])
+		result = b.String()
+	}
+
+	u, err := url.Parse(result)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if len(names) > 0 {
+		base, err := url.Parse(original)
+		if err != nil || base.Scheme != u.Scheme || base.Host != u.Host || u.User != nil {
+			return "", fmt.Errorf("%w: placeholders can only fill the path and query", ErrInvalidTarget)
+		}
+	}
+	if len(query) == 0 || policy == "" || policy == QueryIgnore {
+		return result, nil
+	}
+	u.RawQuery = mergeQuery(u.RawQuery, query, policy == QueryOverride)
+	return u.String(), nil
+}
+
+// mergeQuery adds the query parameters to the raw query of the original URL, keeping its order.
+// With override the parameters of the original URL that are also in query are removed first,
+// otherwise only the parameters it does not have are added.
+func mergeQuery(raw string, query url.Values, override bool) string {
+	var pairs []string
+	existing := make(map[string]bool)
+	for pair := range strings.SplitSeq(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if override && query.Has(key) {
+			continue
+		}
+		existing[key] = true
+		pairs = append(pairs, pair)
+	}
+	added := url.Values{}
+	for key, values := range query {
+		if !existing[key] {
+			added[key] = values
+		}
+	}
+	if encoded := added.Encode(); encoded != "" {
+		pairs = append(pairs, encoded)
+	}
+	return strings.Join(pairs, "&")
+}
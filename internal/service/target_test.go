@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/cmrd-a/shortener/internal/storage/storage_mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		policy   string
+		query    string
+		segments []string
+		want     string
+		wantErr  bool
+	}{
+		{name: "ignore", original: "https://shop.example/?a=1", query: "utm_source=x", want: "https://shop.example/?a=1"},
+		{name: "explicit_ignore", original: "https://shop.example/", policy: QueryIgnore, query: "a=2", want: "https://shop.example/"},
+		{name: "passthrough", original: "https://shop.example/?b=1&a=1", policy: QueryPassthrough, query: "a=2&utm_source=x",
+			want: "https://shop.example/?b=1&a=1&utm_source=x"},
+		{name: "override", original: "https://shop.example/?b=1&a=1", policy: QueryOverride, query: "a=2&utm_source=x",
+			want: "https://shop.example/?b=1&a=2&utm_source=x"},
+		{name: "passthrough_no_query", original: "https://shop.example/p#top", policy: QueryPassthrough, query: "x=1",
+			want: "https://shop.example/p?x=1#top"},
+		{name: "segment", original: "https://shop.example/items/%7Bsku%7D", segments: []string{"12345"},
+			want: "https://shop.example/items/12345"},
+		{name: "query_placeholder", original: "https://shop.example/items/{sku}?ref={ref}", query: "sku=a+b&ref=c%26d&utm=1",
+			want: "https://shop.example/items/a%20b?ref=c%26d"},
+		{name: "segments_then_query", original: "https://shop.example/{cat}/{sku}", policy: QueryPassthrough,
+			query: "sku=9&utm=1", segments: []string{"shoes"}, want: "https://shop.example/shoes/9?utm=1"},
+		{name: "escaped_segment", original: "https://shop.example/{path}", segments: []string{"../a/b"},
+			want: "https://shop.example/..%2Fa%2Fb"},
+		{name: "missing_value", original: "https://shop.example/{sku}", wantErr: true},
+		{name: "extra_segment", original: "https://shop.example/", segments: []string{"x"}, wantErr: true},
+		{name: "host_injection", original: "https://shop.example{p}", segments: []string{"@evil.example"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			got, err := target(tt.original, tt.policy, TargetParams{Query: query, Segments: tt.segments})
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidTarget)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetOriginalTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	ctx := context.TODO()
+	left := int64(1)
+	stored := storage.StoredURL{ShortID: "sku", OriginalURL: "https://shop.example/{sku}", ClicksLeft: &left, QueryPolicy: QueryPassthrough}
+	mr.EXPECT().Get(ctx, "sku").Return(stored, nil).Times(2)
+	mr.EXPECT().ConsumeClick(ctx, "sku").Return(int64(0), nil)
+	svc := NewURLService(NewShortGenerator(), "http://localhost", mr)
+
+	// A request that does not fit the link does not use up its only click.
+	_, err := svc.GetOriginal(ctx, "sku", TargetParams{})
+	require.ErrorIs(t, err, ErrInvalidTarget)
+	redirect, err := svc.GetOriginal(ctx, "sku", TargetParams{Segments: []string{"42"}, Query: url.Values{"utm_source": {"x"}}})
+	require.NoError(t, err)
+	require.Equal(t, "https://shop.example/42?utm_source=x", redirect.URL)
+}
+
+func TestValidateQueryPolicy(t *testing.T) {
+	for _, policy := range []string{QueryIgnore, QueryPassthrough, QueryOverride} {
+		require.NoError(t, ValidateQueryPolicy(policy))
+	}
+	require.ErrorIs(t, ValidateQueryPolicy("merge"), ErrInvalidQueryPolicy)
+	err := ShortenOptions{QueryPolicy: "merge"}.apply(&storage.StoredURL{}, time.Now())
+	require.ErrorIs(t, err, ErrInvalidQueryPolicy)
+}
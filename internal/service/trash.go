@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashedURL represents a deleted link of a user.
+// DeletedAt is nil for links deleted before deletion times were recorded, they can not be restored.
+// RestoreBefore is the moment the link stops being restorable, nil if it already can not be restored.
+type TrashedURL struct {
+	SvcURL
+	DeletedAt     *time.Time
+	RestoreBefore *time.Time
+}
+
+// WithTrashRetention sets for how long deleted links can be restored.
+func WithTrashRetention(d time.Duration) Option {
+	return func(s *URLService) {
+		s.trashRetention = d
+	}
+}
+
+// GetTrash returns the deleted links of the user, most recently deleted first.
+func (s *URLService) GetTrash(ctx context.Context, userID int64) ([]TrashedURL, error) {
+	urls, err := s.repository.GetDeletedUserURLs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	trash := make([]TrashedURL, len(urls))
+	for i, url := range urls {
+		trash[i] = TrashedURL{SvcURL: s.svcURL(url), DeletedAt: url.DeletedAt}
+		if url.DeletedAt == nil {
+			continue
+		}
+		if restoreBefore := url.DeletedAt.Add(s.trashRetention); restoreBefore.After(now) {
+			trash[i].RestoreBefore = &restoreBefore
+		}
+	}
+	return trash, nil
+}
+
+// RestoreUserURLs takes the links of the user deleted within the trash retention period out of the trash.
+// It returns the short IDs of the restored links; the rest are unknown, foreign, not deleted or deleted too long ago.
+// Links still waiting in the deletion queue are not restored.
+func (s *URLService) RestoreUserURLs(ctx context.Context, userID int64, shortIDs ...string) ([]string, error) {
+	return s.repository.RestoreUserURLs(ctx, userID, time.Now().Add(-s.trashRetention), shortIDs...)
+}
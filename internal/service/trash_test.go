@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cmrd-a/shortener/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo, WithTrashRetention(time.Hour))
+	for _, alias := range []string{"keep", "gone"} {
+		_, err := svc.Shorten(ctx, "https://"+alias+".example/", 1, ShortenOptions{Alias: alias})
+		require.NoError(t, err)
+	}
+	repo.MarkDeletedUserURLs(ctx, storage.URLForDelete{ShortID: "gone", UserID: 1})
+
+	trash, err := svc.GetTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, "localhost/gone", trash[0].ShortURL)
+	require.True(t, trash[0].IsDeleted)
+	require.NotNil(t, trash[0].DeletedAt)
+	require.NotNil(t, trash[0].RestoreBefore)
+	require.WithinDuration(t, trash[0].DeletedAt.Add(time.Hour), *trash[0].RestoreBefore, 0)
+
+	restored, err := svc.RestoreUserURLs(ctx, 2, "gone")
+	require.NoError(t, err)
+	require.Empty(t, restored)
+	restored, err = svc.RestoreUserURLs(ctx, 1, "gone", "keep")
+	require.NoError(t, err)
+	require.Equal(t, []string{"gone"}, restored)
+
+	redirect, err := svc.GetOriginal(ctx, "gone", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, "https://gone.example/", redirect.URL)
+	trash, err = svc.GetTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+}
+
+func TestRestoreAfterTrashRetention(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo, WithTrashRetention(time.Nanosecond))
+	_, err := svc.Shorten(ctx, "https://late.example/", 1, ShortenOptions{Alias: "late"})
+	require.NoError(t, err)
+	repo.MarkDeletedUserURLs(ctx, storage.URLForDelete{ShortID: "late", UserID: 1})
+	time.Sleep(time.Millisecond)
+
+	trash, err := svc.GetTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Nil(t, trash[0].RestoreBefore)
+	restored, err := svc.RestoreUserURLs(ctx, 1, "late")
+	require.NoError(t, err)
+	require.Empty(t, restored)
+}
@@ -4,150 +4,568 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cmrd-a/shortener/internal/storage"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
 
-	"math/rand"
+const (
+	defaultMaxRetries          = 3
+	defaultExpirySweepInterval = time.Minute
 )
 
-var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+// URLService provides URL shortening and management functionality.
+type URLService struct {
+	generator       Generator
+	baseURL         string
+	repository      storage.Repository
+	delUserURLsChan chan deletionRequest
+	deletionJobs    deletionJobs
+	deletionBuffer  int
+	deletionFlush   time.Duration
+	deletionBatch   int
+	delWaiting      atomic.Int64
+	maxRetries      int
+	expirySweep     time.Duration
+	clicksChan      chan storage.ClickEvent
+	clickFlush      time.Duration
+	anonymizeIP     bool
+	normalizer      *URLNormalizer
+	blocker         Blocker
+	defaultRedirect int
+	trashRetention  time.Duration
+	purgeRetention  time.Duration
+	purgeInterval   time.Duration
+	purge           purgeState
+	deletionQueue   storage.DeletionQueue
+	ctx             context.Context
+	jobs            sync.WaitGroup
+	log             *zap.Logger
+}
 
-// Generator defines an interface for generating short URL identifiers.
-type Generator interface {
-	Generate() string
+// Blocker decides whether an original URL must not be shortened or followed.
+// Blocked returns the matched rule for logging.
+type Blocker interface {
+	Blocked(rawURL string) (rule string, blocked bool)
 }
 
-// ShortGenerator implements the Generator interface using random letter combinations.
-type ShortGenerator struct{}
+// Option configures optional URLService settings.
+type Option func(*URLService)
 
-// NewShortGenerator creates a new ShortGenerator instance.
-func NewShortGenerator() *ShortGenerator {
-	return &ShortGenerator{}
+// WithMaxRetries sets how many times a short ID is regenerated after a collision.
+func WithMaxRetries(n int) Option {
+	return func(s *URLService) {
+		s.maxRetries = n
+	}
 }
 
-// Generate creates a random 5-character string using letters.
-func (g *ShortGenerator) Generate() string {
-	b := make([]rune, 5)
-	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
+// WithExpirySweepInterval sets how often expired links are marked in storage.
+// Non-positive intervals are ignored.
+func WithExpirySweepInterval(d time.Duration) Option {
+	return func(s *URLService) {
+		if d > 0 {
+			s.expirySweep = d
+		}
 	}
-	return string(b)
 }
 
-// URLService provides URL shortening and management functionality.
-type URLService struct {
-	generator       Generator
-	baseURL         string
-	repository      storage.Repository
-	delUserURLsChan chan storage.URLForDelete
+// WithURLNormalizer sets the validation and normalization rules of original URLs.
+func WithURLNormalizer(n *URLNormalizer) Option {
+	return func(s *URLService) {
+		s.normalizer = n
+	}
+}
+
+// WithBlocker makes the service refuse to shorten and to follow URLs matched by the blocker.
+func WithBlocker(b Blocker) Option {
+	return func(s *URLService) {
+		s.blocker = b
+	}
+}
+
+// WithContext sets the context the background jobs run under.
+// Once it is canceled the jobs flush what they hold and stop, see Wait.
+func WithContext(ctx context.Context) Option {
+	return func(s *URLService) {
+		s.ctx = ctx
+	}
+}
+
+// WithDeletionQueue makes accepted deletion requests durable: they are pushed to the queue
+// before being accepted and removed from it once applied. Pending requests are replayed on start.
+func WithDeletionQueue(q storage.DeletionQueue) Option {
+	return func(s *URLService) {
+		s.deletionQueue = q
+	}
+}
+
+// WithLogger sets the logger used by background jobs.
+func WithLogger(log *zap.Logger) Option {
+	return func(s *URLService) {
+		s.log = log
+	}
 }
 
 // NewURLService creates a new URLService instance with the provided dependencies.
-// It starts a background goroutine for handling URL deletion requests.
-func NewURLService(generator Generator, baseURL string, repo storage.Repository) *URLService {
+// It starts background goroutines for handling URL deletion requests, marking expired links and storing clicks,
+// and for purging old links if a purge retention is set.
+func NewURLService(generator Generator, baseURL string, repo storage.Repository, opts ...Option) *URLService {
 	s := URLService{
 		generator:       generator,
 		baseURL:         baseURL,
 		repository:      repo,
-		delUserURLsChan: make(chan storage.URLForDelete, 1024),
+		deletionJobs:    deletionJobs{jobs: make(map[string]*DeletionJob)},
+		deletionBuffer:  defaultDeletionBufferSize,
+		deletionFlush:   defaultDeletionFlushInterval,
+		deletionBatch:   defaultDeletionBatchSize,
+		maxRetries:      defaultMaxRetries,
+		expirySweep:     defaultExpirySweepInterval,
+		clicksChan:      make(chan storage.ClickEvent, clickBufferSize),
+		clickFlush:      defaultClickFlush,
+		normalizer:      NewURLNormalizer(nil, 0, false),
+		defaultRedirect: http.StatusTemporaryRedirect,
+		trashRetention:  defaultTrashRetention,
+		purgeInterval:   defaultPurgeInterval,
+		ctx:             context.Background(),
+		log:             zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	s.delUserURLsChan = make(chan deletionRequest, s.deletionBuffer)
+	s.startJob(s.deleteUserURLsJob)
+	s.startJob(s.expireURLsJob)
+	s.startJob(s.clicksJob)
+	if s.purgeRetention > 0 {
+		s.startJob(s.purgeJob)
 	}
-	go s.deleteUserURLsJob()
 	return &s
 }
 
+// generate returns a new short ID for the given attempt.
+// Retries get longer IDs if the generator supports it, otherwise a fresh ID of the usual length.
+func (s *URLService) generate(attempt int) string {
+	if attempt == 0 {
+		return s.generator.Generate()
+	}
+	if e, ok := s.generator.(Extender); ok {
+		return e.GenerateExtended(attempt)
+	}
+	return s.generator.Generate()
+}
+
+// checkBlocked returns ErrURLBlocked and logs the matched rule if the original URL is blocked.
+// Only the host of the URL is logged, as its path and query may carry secrets of the link owner.
+func (s *URLService) checkBlocked(originalURL, action string) error {
+	if s.blocker == nil {
+		return nil
+	}
+	rule, blocked := s.blocker.Blocked(originalURL)
+	if !blocked {
+		return nil
+	}
+	s.log.Warn("blocked url",
+		zap.String("action", action),
+		zap.String("host", urlHost(originalURL)),
+		zap.String("rule", rule),
+	)
+	return ErrURLBlocked
+}
+
+// urlHost returns the host of a URL without the port, or nothing if it does not parse.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
 func (s *URLService) addBaseURL(shortID string) string {
 	return fmt.Sprintf("%s/%s", s.baseURL, shortID)
 }
 
 // Shorten creates a shortened URL for the given original URL and user ID.
+// The original URL is normalized first, so equivalent spellings are deduplicated.
+// If opts.Alias is set it is used as the short ID, otherwise one is generated.
 // Returns the full shortened URL or an error if the operation fails.
-func (s *URLService) Shorten(ctx context.Context, originalURL string, userID int64) (string, error) {
-	shortID := s.generator.Generate()
-	err := s.repository.Add(ctx, shortID, originalURL, userID)
-	var myErr *storage.ErrOriginalExist
-	if errors.As(err, &myErr) {
-		return "", NewOriginalExistError(s.addBaseURL(myErr.Short))
-	}
+func (s *URLService) Shorten(ctx context.Context, originalURL string, userID int64, opts ShortenOptions) (string, error) {
+	originalURL, err := s.normalizer.Normalize(originalURL)
 	if err != nil {
 		return "", err
 	}
-	shortURL := s.addBaseURL(shortID)
-	return shortURL, nil
+	if err := s.checkBlocked(originalURL, "shorten"); err != nil {
+		return "", err
+	}
+	if opts.Alias != "" {
+		if err := ValidateAlias(opts.Alias); err != nil {
+			return "", err
+		}
+	}
+	stored := storage.StoredURL{OriginalURL: originalURL, UserID: userID}
+	if err := opts.apply(&stored, time.Now()); err != nil {
+		return "", err
+	}
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		stored.ShortID = opts.Alias
+		if stored.ShortID == "" {
+			stored.ShortID = s.generate(attempt)
+		}
+		err := s.repository.Add(ctx, stored)
+		var myErr *storage.ErrOriginalExist
+		if errors.As(err, &myErr) {
+			return "", NewOriginalExistError(s.addBaseURL(myErr.Short))
+		}
+		var shortErr *storage.ErrShortExist
+		if errors.As(err, &shortErr) {
+			if opts.Alias != "" {
+				return "", fmt.Errorf("%w: %s", ErrAliasTaken, opts.Alias)
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return s.addBaseURL(stored.ShortID), nil
+	}
+	return "", ErrShortIDExhausted
 }
 
 // ShortenBatch creates shortened URLs for multiple original URLs in a single operation.
-// Takes a map of correlation IDs to original URLs and returns a map of correlation IDs to shortened URLs.
-func (s *URLService) ShortenBatch(ctx context.Context, userID int64, corOriginals map[string]string) (map[string]string, error) {
-	shorts := make(map[string]string, len(corOriginals))
-	shortsOriginals := make([]storage.StoredURL, 0)
-	for corrID, original := range corOriginals {
-		short := s.generator.Generate()
-		shorts[corrID] = short
-		shortsOriginals = append(shortsOriginals, storage.StoredURL{ShortID: short, OriginalURL: original, UserID: userID})
+// Takes a map of correlation IDs to batch items and returns a map of correlation IDs to shortened URLs.
+// Items whose original is already stored within the dedup scope get the existing short URL, like
+// Shorten reports with ErrOriginalExist; an original repeated in the batch is stored once and shared
+// unless deduplication is off.
+// A generated short ID that turns out to be occupied is regenerated and the whole batch is retried,
+// while an occupied alias fails the batch with an AliasTakenError.
+func (s *URLService) ShortenBatch(ctx context.Context, userID int64, items map[string]BatchItem) (map[string]string, error) {
+	stored, err := s.shortenBatch(ctx, userID, items)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(stored))
+	for corrID, item := range stored {
+		result[corrID] = s.addBaseURL(item.short)
+	}
+	return result, nil
+}
+
+// batchStored is the short ID an item of a batch got and whether the item was stored by the batch
+// rather than resolved to a URL already stored for its original.
+type batchStored struct {
+	short   string
+	created bool
+}
+
+// shortenBatch does the work of ShortenBatch and returns the short IDs along with whether each item was newly stored.
+func (s *URLService) shortenBatch(ctx context.Context, userID int64, items map[string]BatchItem) (map[string]batchStored, error) {
+	corrIDs := make([]string, 0, len(items))
+	for corrID := range items {
+		corrIDs = append(corrIDs, corrID)
+	}
+	slices.Sort(corrIDs)
+
+	now := time.Now()
+	aliases := make(map[string]struct{})
+	prepared := make(map[string]storage.StoredURL, len(corrIDs))
+	for _, corrID := range corrIDs {
+		item := items[corrID]
+		if item.Alias != "" {
+			if _, ok := aliases[item.Alias]; ok {
+				return nil, fmt.Errorf("%w: %s is used twice", ErrInvalidAlias, item.Alias)
+			}
+			aliases[item.Alias] = struct{}{}
+		}
+		stored, err := s.prepare(userID, item, now)
+		if err != nil {
+			return nil, err
+		}
+		prepared[corrID] = stored
 	}
 
-	err := s.repository.AddBatch(ctx, userID, shortsOriginals...)
+	// Like Import, each round stores the first item of every original, so that the repeats can be
+	// resolved against it in the next round.
+	result := make(map[string]batchStored, len(corrIDs))
+	pending := corrIDs
+	for races := 0; len(pending) > 0; {
+		var chunk, deferred []string
+		originals := make(map[string]struct{})
+		for _, corrID := range pending {
+			original := prepared[corrID].OriginalURL
+			if _, ok := originals[original]; ok {
+				deferred = append(deferred, corrID)
+				continue
+			}
+			originals[original] = struct{}{}
+			chunk = append(chunk, corrID)
+		}
+		stored, err := s.storeBatch(ctx, userID, chunk, prepared)
+		var origErr *storage.ErrOriginalExist
+		if errors.As(err, &origErr) && races < s.maxRetries {
+			// Stored concurrently since it was looked up, so the same round finds it when repeated.
+			races++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for corrID, item := range stored {
+			result[corrID] = item
+		}
+		pending = deferred
+	}
+	return result, nil
+}
+
+// storeBatch stores the prepared items of corrIDs, which all have different originals, and returns their short IDs.
+// Items whose original is already stored within the dedup scope are not stored and get the existing short ID.
+func (s *URLService) storeBatch(ctx context.Context, userID int64, corrIDs []string, prepared map[string]storage.StoredURL) (map[string]batchStored, error) {
+	originals := make([]string, len(corrIDs))
+	for i, corrID := range corrIDs {
+		originals[i] = prepared[corrID].OriginalURL
+	}
+	existing, err := s.repository.FindOriginals(ctx, userID, originals...)
 	if err != nil {
 		return nil, err
 	}
-
-	result := make(map[string]string, len(corOriginals))
-	for corrID := range corOriginals {
-		result[corrID] = s.addBaseURL(shorts[corrID])
+	result := make(map[string]batchStored, len(corrIDs))
+	newIDs := make([]string, 0, len(corrIDs))
+	shortsOriginals := make([]storage.StoredURL, 0, len(corrIDs))
+	for _, corrID := range corrIDs {
+		stored := prepared[corrID]
+		if short, ok := existing[stored.OriginalURL]; ok {
+			result[corrID] = batchStored{short: short}
+			continue
+		}
+		if stored.ShortID == "" {
+			stored.ShortID = s.generator.Generate()
+		}
+		newIDs = append(newIDs, corrID)
+		shortsOriginals = append(shortsOriginals, stored)
+	}
+	if len(shortsOriginals) == 0 {
+		return result, nil
+	}
+	for attempt := 0; ; attempt++ {
+		err := s.repository.AddBatch(ctx, userID, shortsOriginals...)
+		var shortErr *storage.ErrShortExist
+		if !errors.As(err, &shortErr) {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		if attempt == s.maxRetries {
+			return nil, ErrShortIDExhausted
+		}
+		regenerated := false
+		for i, corrID := range newIDs {
+			if shortsOriginals[i].ShortID == shortErr.Short && prepared[corrID].ShortID == "" {
+				shortsOriginals[i].ShortID = s.generate(attempt + 1)
+				regenerated = true
+			}
+		}
+		if !regenerated {
+			return nil, &AliasTakenError{Alias: shortErr.Short}
+		}
+	}
+	for i, corrID := range newIDs {
+		result[corrID] = batchStored{short: shortsOriginals[i].ShortID, created: true}
 	}
 	return result, nil
 }
 
-// GetOriginal retrieves the original URL for a given short URL identifier.
-func (s *URLService) GetOriginal(ctx context.Context, id string) (string, error) {
-	original, err := s.repository.Get(ctx, id)
-	return original, err
+// prepare validates a batch item and builds the URL record to store, without a generated short ID.
+func (s *URLService) prepare(userID int64, item BatchItem, now time.Time) (storage.StoredURL, error) {
+	if item.Alias != "" {
+		if err := ValidateAlias(item.Alias); err != nil {
+			return storage.StoredURL{}, err
+		}
+	}
+	originalURL, err := s.normalizer.Normalize(item.OriginalURL)
+	if err != nil {
+		return storage.StoredURL{}, err
+	}
+	if err := s.checkBlocked(originalURL, "shorten"); err != nil {
+		return storage.StoredURL{}, err
+	}
+	stored := storage.StoredURL{ShortID: item.Alias, OriginalURL: originalURL, UserID: userID}
+	if err := item.apply(&stored, now); err != nil {
+		return storage.StoredURL{}, err
+	}
+	return stored, nil
 }
 
-// Ping checks the health of the underlying storage repository.
-func (s *URLService) Ping(ctx context.Context) error {
-	return s.repository.Ping(ctx)
+// ShortURL returns the full short URL of an existing link without following it.
+// Deleted, expired and exhausted links return the storage error.
+func (s *URLService) ShortURL(ctx context.Context, id string) (string, error) {
+	if _, err := s.repository.Get(ctx, id); err != nil {
+		return "", err
+	}
+	return s.addBaseURL(id), nil
 }
 
-// GetUserURLs retrieves all URLs created by a specific user.
-func (s *URLService) GetUserURLs(ctx context.Context, id int64) ([]SvcURL, error) {
-	storedURLs, err := s.repository.GetUserURLs(ctx, id)
+// Preview returns a link for a page that shows its destination instead of redirecting.
+// The OriginalURL of the result is the URL the request params would redirect to.
+// It does not use up clicks. Like GetOriginal it returns ErrURLBlocked for blocked URLs
+// and ErrPasswordRequired for protected links, whose destination is not revealed.
+func (s *URLService) Preview(ctx context.Context, id string, params TargetParams) (SvcURL, error) {
+	stored, err := s.repository.Get(ctx, id)
 	if err != nil {
-		return nil, err
+		return SvcURL{}, err
+	}
+	if err := s.checkBlocked(stored.OriginalURL, "preview"); err != nil {
+		return SvcURL{}, err
+	}
+	if stored.PasswordHash != "" {
+		return SvcURL{}, ErrPasswordRequired
+	}
+	link := s.svcURL(stored)
+	if link.OriginalURL, err = target(stored.OriginalURL, stored.QueryPolicy, params); err != nil {
+		return SvcURL{}, err
+	}
+	return link, nil
+}
+
+// GetOriginal retrieves the redirect to the original URL for a given short URL identifier.
+// Following a click-limited link uses up one of its clicks.
+// Password-protected links are never resolved here, ErrPasswordRequired is returned instead.
+// Links to blocked URLs return ErrURLBlocked.
+// The redirect goes to the original URL filled in and merged with the params, see TargetParams;
+// params that do not fit the link return ErrInvalidTarget without using up a click.
+func (s *URLService) GetOriginal(ctx context.Context, id string, params TargetParams) (Redirect, error) {
+	stored, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Redirect{}, err
+	}
+	if err := s.checkBlocked(stored.OriginalURL, "redirect"); err != nil {
+		return Redirect{}, err
+	}
+	if stored.PasswordHash != "" {
+		return Redirect{}, ErrPasswordRequired
+	}
+	to, err := target(stored.OriginalURL, stored.QueryPolicy, params)
+	if err != nil {
+		return Redirect{}, err
+	}
+	if err := s.follow(ctx, stored); err != nil {
+		return Redirect{}, err
+	}
+	redirect := s.redirect(stored, time.Now())
+	redirect.URL = to
+	return redirect, nil
+}
+
+// Unlock retrieves the original URL of a password-protected link if the password matches.
+// Public links are resolved as by GetOriginal.
+func (s *URLService) Unlock(ctx context.Context, id, password string, params TargetParams) (string, error) {
+	stored, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkBlocked(stored.OriginalURL, "redirect"); err != nil {
+		return "", err
+	}
+	if stored.PasswordHash != "" {
+		err := bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(password))
+		if err != nil {
+			return "", ErrWrongPassword
+		}
+	}
+	to, err := target(stored.OriginalURL, stored.QueryPolicy, params)
+	if err != nil {
+		return "", err
 	}
-	if len(storedURLs) == 0 {
-		return nil, nil
+	if err := s.follow(ctx, stored); err != nil {
+		return "", err
 	}
-	svcURLs := make([]SvcURL, len(storedURLs))
-	for i, stored := range storedURLs {
-		svcURLs[i] = SvcURL{OriginalURL: stored.OriginalURL, UserID: stored.UserID, ShortURL: s.addBaseURL(stored.ShortID)}
+	return to, nil
+}
+
+// follow uses up a click of a click-limited link.
+func (s *URLService) follow(ctx context.Context, stored storage.StoredURL) error {
+	if stored.ClicksLeft != nil {
+		if _, err := s.repository.ConsumeClick(ctx, stored.ShortID); err != nil {
+			return err
+		}
 	}
-	return svcURLs, nil
+	return nil
+}
+
+// Ping checks the health of the underlying storage repository.
+func (s *URLService) Ping(ctx context.Context) error {
+	return s.repository.Ping(ctx)
 }
 
-// DeleteUserURLs queues URLs for deletion by sending them to the deletion channel.
-// The actual deletion is handled asynchronously by a background goroutine.
-func (s *URLService) DeleteUserURLs(ctx context.Context, userID int64, shortIDs ...string) {
-	for _, shortID := range shortIDs {
-		s.delUserURLsChan <- storage.URLForDelete{UserID: userID, ShortID: shortID}
+func (s *URLService) svcURL(stored storage.StoredURL) SvcURL {
+	u := SvcURL{
+		OriginalURL:  stored.OriginalURL,
+		UserID:       stored.UserID,
+		ShortURL:     s.addBaseURL(stored.ShortID),
+		IsDeleted:    stored.IsDeleted,
+		ExpiresAt:    stored.ExpiresAt,
+		ClicksLeft:   stored.ClicksLeft,
+		RedirectType: stored.RedirectType,
+		QueryPolicy:  stored.QueryPolicy,
+		Title:        stored.Title,
+		Notes:        stored.Notes,
+		Tags:         stored.Tags,
 	}
+	if !stored.CreatedAt.IsZero() {
+		u.CreatedAt = &stored.CreatedAt
+	}
+	return u
+}
+
+// startJob runs the background job in a goroutine tracked by Wait.
+func (s *URLService) startJob(job func()) {
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		job()
+	}()
 }
 
-func (s *URLService) deleteUserURLsJob() {
-	ticker := time.NewTicker(5 * time.Second)
+// Wait blocks until the background jobs have flushed and stopped after the service context was canceled,
+// or until ctx is done.
+func (s *URLService) Wait(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-	var deletions []storage.URLForDelete
+// expireURLsJob periodically marks links whose expiry time has passed until the service context is canceled.
+func (s *URLService) expireURLsJob() {
+	ticker := time.NewTicker(s.expirySweep)
+	defer ticker.Stop()
 	for {
 		select {
-		case deletion := <-s.delUserURLsChan:
-			deletions = append(deletions, deletion)
 		case <-ticker.C:
-			if len(deletions) == 0 {
-				continue
-			}
-			s.repository.MarkDeletedUserURLs(context.Background(), deletions...)
-			deletions = nil
+		case <-s.ctx.Done():
+			return
+		}
+		count, err := s.repository.MarkExpiredURLs(context.Background(), time.Now())
+		if err != nil {
+			s.log.Error("failed to mark expired urls", zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			s.log.Info("marked expired urls", zap.Int64("count", count))
 		}
 	}
 }
@@ -3,13 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cmrd-a/shortener/internal/service/service_mocks"
 	"github.com/cmrd-a/shortener/internal/storage"
 	"github.com/cmrd-a/shortener/internal/storage/storage_mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestGetOriginal(t *testing.T) {
@@ -21,13 +27,31 @@ func TestGetOriginal(t *testing.T) {
 	value := "ya.ru"
 	short := "RaNdOm"
 	ctx := context.TODO()
-	mr.EXPECT().Get(ctx, short).Return(value, nil)
+	mr.EXPECT().Get(ctx, short).Return(storage.StoredURL{ShortID: short, OriginalURL: value}, nil)
 	generator := NewShortGenerator()
 	svc := NewURLService(generator, "localhost", mr)
-	original, err := svc.GetOriginal(ctx, short)
+	redirect, err := svc.GetOriginal(ctx, short, TargetParams{})
 
 	require.NoError(t, err)
-	require.Equal(t, original, value)
+	require.Equal(t, redirect.URL, value)
+	require.Equal(t, http.StatusTemporaryRedirect, redirect.StatusCode)
+}
+
+func TestShortURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	ctx := context.TODO()
+	mr.EXPECT().Get(ctx, "RaNdOm").Return(storage.StoredURL{ShortID: "RaNdOm", OriginalURL: "ya.ru"}, nil)
+	mr.EXPECT().Get(ctx, "gone").Return(storage.StoredURL{}, storage.ErrURLIsDeleted)
+	svc := NewURLService(NewShortGenerator(), "http://localhost", mr)
+
+	shortURL, err := svc.ShortURL(ctx, "RaNdOm")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost/RaNdOm", shortURL)
+	_, err = svc.ShortURL(ctx, "gone")
+	require.ErrorIs(t, err, storage.ErrURLIsDeleted)
 }
 
 func TestShortenBatch(t *testing.T) {
@@ -43,15 +67,16 @@ func TestShortenBatch(t *testing.T) {
 	mg.EXPECT().Generate().Return(s1)
 	mg.EXPECT().Generate().Return(s2)
 
-	corOriginals := make(map[string]string, 2)
-	corOriginals["cor1"] = o1
-	corOriginals["cor2"] = o2
+	corOriginals := make(map[string]BatchItem, 2)
+	corOriginals["cor1"] = BatchItem{OriginalURL: o1}
+	corOriginals["cor2"] = BatchItem{OriginalURL: o2}
 	ctx := context.TODO()
 	var userID int64 = 1
 	storedURLs := make([]storage.StoredURL, 0)
 	storedURLs = append(storedURLs, storage.StoredURL{ShortID: s1, OriginalURL: o1, UserID: userID})
 	storedURLs = append(storedURLs, storage.StoredURL{ShortID: s2, OriginalURL: o2, UserID: userID})
 
+	mr.EXPECT().FindOriginals(ctx, userID, o1, o2).Return(map[string]string{}, nil)
 	mr.EXPECT().AddBatch(ctx, userID, storedURLs).Return(nil)
 	svc := NewURLService(mg, "localhost", mr)
 	shorts, err := svc.ShortenBatch(ctx, userID, corOriginals)
@@ -62,3 +87,309 @@ func TestShortenBatch(t *testing.T) {
 	expected["cor2"] = fmt.Sprintf("localhost/%s", s2)
 	require.Equal(t, shorts, expected)
 }
+
+func TestShortenRetriesOnShortCollision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	mg := service_mocks.NewMockGenerator(ctrl)
+	ctx := context.TODO()
+	var userID int64 = 1
+	original := "https://go.dev"
+
+	gomock.InOrder(
+		mg.EXPECT().Generate().Return("taken"),
+		mr.EXPECT().Add(ctx, storage.StoredURL{ShortID: "taken", OriginalURL: original, UserID: userID}).Return(storage.NewShortExistError("taken")),
+		mg.EXPECT().Generate().Return("free"),
+		mr.EXPECT().Add(ctx, storage.StoredURL{ShortID: "free", OriginalURL: original, UserID: userID}).Return(nil),
+	)
+
+	svc := NewURLService(mg, "localhost", mr)
+	short, err := svc.Shorten(ctx, original, userID, ShortenOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "localhost/free", short)
+}
+
+func TestShortenGivesUpAfterMaxRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	mg := service_mocks.NewMockGenerator(ctrl)
+	ctx := context.TODO()
+
+	mg.EXPECT().Generate().Return("taken").Times(3)
+	mr.EXPECT().Add(ctx, gomock.Any()).Return(storage.NewShortExistError("taken")).Times(3)
+
+	svc := NewURLService(mg, "localhost", mr, WithMaxRetries(2))
+	_, err := svc.Shorten(ctx, "https://go.dev", 1, ShortenOptions{})
+	require.ErrorIs(t, err, ErrShortIDExhausted)
+}
+
+func TestShortenEscalatesIDLength(t *testing.T) {
+	repo := storage.NewInMemoryRepository()
+	ctx := context.TODO()
+	generator := NewSeededGenerator(7, 4)
+	taken := NewSeededGenerator(7, 4).Generate()
+	require.NoError(t, repo.Add(ctx, storage.StoredURL{ShortID: taken, OriginalURL: "https://taken.example", UserID: 1}))
+
+	svc := NewURLService(generator, "localhost", repo)
+	short, err := svc.Shorten(ctx, "https://go.dev", 1, ShortenOptions{})
+	require.NoError(t, err)
+	require.Len(t, short, len("localhost/")+5)
+}
+
+func TestShortenBatchRetriesOnShortCollision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	mg := service_mocks.NewMockGenerator(ctrl)
+	ctx := context.TODO()
+	var userID int64 = 1
+	o1 := "https://regex101.com"
+	o2 := "https://www.jaegertracing.io"
+
+	gomock.InOrder(
+		mr.EXPECT().FindOriginals(ctx, userID, o1, o2).Return(map[string]string{}, nil),
+		mg.EXPECT().Generate().Return("short1"),
+		mg.EXPECT().Generate().Return("taken"),
+		mr.EXPECT().AddBatch(ctx, userID, []storage.StoredURL{
+			{ShortID: "short1", OriginalURL: o1, UserID: userID},
+			{ShortID: "taken", OriginalURL: o2, UserID: userID},
+		}).Return(storage.NewShortExistError("taken")),
+		mg.EXPECT().Generate().Return("short2"),
+		mr.EXPECT().AddBatch(ctx, userID, []storage.StoredURL{
+			{ShortID: "short1", OriginalURL: o1, UserID: userID},
+			{ShortID: "short2", OriginalURL: o2, UserID: userID},
+		}).Return(nil),
+	)
+
+	svc := NewURLService(mg, "localhost", mr)
+	shorts, err := svc.ShortenBatch(ctx, userID, map[string]BatchItem{"cor1": {OriginalURL: o1}, "cor2": {OriginalURL: o2}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"cor1": "localhost/short1", "cor2": "localhost/short2"}, shorts)
+}
+
+func TestShortenBatchDedup(t *testing.T) {
+	ctx := context.TODO()
+	for _, tt := range []struct {
+		scope        storage.DedupScope
+		wantExisting bool
+	}{
+		{storage.DedupGlobal, true},
+		{storage.DedupUser, false},
+		{storage.DedupOff, false},
+	} {
+		repo := storage.NewInMemoryRepository(storage.WithDedupScope(tt.scope))
+		svc := NewURLService(NewShortGenerator(), "localhost", repo)
+		existing, err := svc.Shorten(ctx, "https://go.dev", 2, ShortenOptions{})
+		require.NoError(t, err)
+
+		shorts, err := svc.ShortenBatch(ctx, 1, map[string]BatchItem{
+			"existing": {OriginalURL: "https://go.dev"},
+			"first":    {OriginalURL: "https://pkg.go.dev"},
+			"repeated": {OriginalURL: "https://PKG.go.dev"},
+		})
+		require.NoError(t, err, tt.scope)
+		require.Len(t, shorts, 3)
+		require.Equal(t, tt.wantExisting, shorts["existing"] == existing, tt.scope)
+		require.Equal(t, tt.scope != storage.DedupOff, shorts["first"] == shorts["repeated"], tt.scope)
+
+		again, err := svc.ShortenBatch(ctx, 1, map[string]BatchItem{"again": {OriginalURL: "https://pkg.go.dev"}})
+		require.NoError(t, err, tt.scope)
+		require.Equal(t, tt.scope != storage.DedupOff, again["again"] == shorts["first"], tt.scope)
+	}
+}
+
+func TestShortenAgainAfterExpiry(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+	first, err := svc.Shorten(ctx, "https://ttl.example", 1, ShortenOptions{ExpiresAt: time.Now().Add(50 * time.Millisecond)})
+	require.NoError(t, err)
+	var existErr *OriginalExistError
+	_, err = svc.Shorten(ctx, "https://ttl.example", 1, ShortenOptions{})
+	require.ErrorAs(t, err, &existErr)
+	time.Sleep(100 * time.Millisecond)
+
+	second, err := svc.Shorten(ctx, "https://ttl.example", 1, ShortenOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+	shorts, err := svc.ShortenBatch(ctx, 1, map[string]BatchItem{"1": {OriginalURL: "https://ttl.example"}})
+	require.NoError(t, err)
+	require.Equal(t, second, shorts["1"])
+}
+
+func TestShortenBatchResolvesConcurrentOriginal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	mg := service_mocks.NewMockGenerator(ctrl)
+	ctx := context.TODO()
+	var userID int64 = 1
+	o1 := "https://regex101.com"
+	o2 := "https://www.jaegertracing.io"
+
+	gomock.InOrder(
+		mr.EXPECT().FindOriginals(ctx, userID, o1, o2).Return(map[string]string{}, nil),
+		mg.EXPECT().Generate().Return("short1"),
+		mg.EXPECT().Generate().Return("short2"),
+		mr.EXPECT().AddBatch(ctx, userID, []storage.StoredURL{
+			{ShortID: "short1", OriginalURL: o1, UserID: userID},
+			{ShortID: "short2", OriginalURL: o2, UserID: userID},
+		}).Return(storage.NewOriginalExistError("other")),
+		mr.EXPECT().FindOriginals(ctx, userID, o1, o2).Return(map[string]string{o2: "other"}, nil),
+		mg.EXPECT().Generate().Return("short3"),
+		mr.EXPECT().AddBatch(ctx, userID, []storage.StoredURL{
+			{ShortID: "short3", OriginalURL: o1, UserID: userID},
+		}).Return(nil),
+	)
+
+	svc := NewURLService(mg, "localhost", mr)
+	shorts, err := svc.ShortenBatch(ctx, userID, map[string]BatchItem{"cor1": {OriginalURL: o1}, "cor2": {OriginalURL: o2}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"cor1": "localhost/short3", "cor2": "localhost/other"}, shorts)
+}
+
+func TestShortenExpiry(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+
+	_, err := svc.Shorten(ctx, "https://in.example", 1, ShortenOptions{ExpiresIn: time.Hour, ExpiresAt: time.Now().Add(time.Hour)})
+	require.ErrorIs(t, err, ErrInvalidExpiry)
+
+	_, err = svc.Shorten(ctx, "https://past.example", 1, ShortenOptions{ExpiresAt: time.Now().Add(-time.Hour)})
+	require.ErrorIs(t, err, ErrInvalidExpiry)
+
+	_, err = svc.Shorten(ctx, "https://ttl.example", 1, ShortenOptions{ExpiresIn: time.Hour})
+	require.NoError(t, err)
+	page, err := svc.GetUserURLs(ctx, 1, storage.URLQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.URLs, 1)
+	require.NotNil(t, page.URLs[0].ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *page.URLs[0].ExpiresAt, time.Minute)
+}
+
+func TestShortenPassword(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	svc := NewURLService(NewShortGenerator(), "localhost", repo)
+
+	_, err := svc.Shorten(ctx, "https://long.example", 1, ShortenOptions{Alias: "long-pass", Password: strings.Repeat("x", 100)})
+	require.ErrorIs(t, err, ErrInvalidPassword)
+
+	_, err = svc.Shorten(ctx, "https://secret.example", 1, ShortenOptions{Alias: "secret", Password: "hunter2"})
+	require.NoError(t, err)
+	stored, err := repo.Get(ctx, "secret")
+	require.NoError(t, err)
+	require.NotEmpty(t, stored.PasswordHash)
+	require.NotEqual(t, "hunter2", stored.PasswordHash)
+
+	_, err = svc.GetOriginal(ctx, "secret", TargetParams{})
+	require.ErrorIs(t, err, ErrPasswordRequired)
+	_, err = svc.Unlock(ctx, "secret", "wrong", TargetParams{})
+	require.ErrorIs(t, err, ErrWrongPassword)
+	original, err := svc.Unlock(ctx, "secret", "hunter2", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, "https://secret.example", original)
+}
+
+func TestShortenNormalizesBeforeDedup(t *testing.T) {
+	ctx := context.TODO()
+	svc := NewURLService(NewShortGenerator(), "localhost", storage.NewInMemoryRepository())
+
+	short, err := svc.Shorten(ctx, "http://example.com/", 1, ShortenOptions{})
+	require.NoError(t, err)
+
+	_, err = svc.Shorten(ctx, "HTTP://Example.com:80/", 1, ShortenOptions{})
+	var existErr *OriginalExistError
+	require.ErrorAs(t, err, &existErr)
+	require.Equal(t, short, existErr.Short)
+
+	_, err = svc.Shorten(ctx, "javascript:alert(1)", 1, ShortenOptions{})
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = svc.ShortenBatch(ctx, 1, map[string]BatchItem{"1": {OriginalURL: "not a url"}})
+	require.ErrorIs(t, err, ErrInvalidURL)
+}
+
+type hostBlocker string
+
+func (b hostBlocker) Blocked(rawURL string) (string, bool) {
+	return string(b), strings.Contains(rawURL, string(b))
+}
+
+func TestDenylistBlocksShortenAndRedirect(t *testing.T) {
+	ctx := context.TODO()
+	repo := storage.NewInMemoryRepository()
+	err := repo.Add(ctx, storage.StoredURL{ShortID: "old", OriginalURL: "https://evil.example/old", UserID: 1})
+	require.NoError(t, err)
+	core, logs := observer.New(zap.WarnLevel)
+	svc := NewURLService(NewShortGenerator(), "localhost", repo, WithBlocker(hostBlocker("evil.example")), WithLogger(zap.New(core)))
+
+	_, err = svc.Shorten(ctx, "https://evil.example/new", 1, ShortenOptions{})
+	require.ErrorIs(t, err, ErrURLBlocked)
+	_, err = svc.ShortenBatch(ctx, 1, map[string]BatchItem{"1": {OriginalURL: "https://evil.example/batch"}})
+	require.ErrorIs(t, err, ErrURLBlocked)
+
+	_, err = svc.GetOriginal(ctx, "old", TargetParams{})
+	require.ErrorIs(t, err, ErrURLBlocked)
+	_, err = svc.Unlock(ctx, "old", "", TargetParams{})
+	require.ErrorIs(t, err, ErrURLBlocked)
+
+	_, err = svc.Shorten(ctx, "https://good.example/", 1, ShortenOptions{})
+	require.NoError(t, err)
+
+	// Paths and queries may hold secrets, so only the host is logged.
+	require.Equal(t, 4, logs.Len())
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		require.Equal(t, "evil.example", fields["host"])
+		require.NotContains(t, fmt.Sprint(fields), "/old")
+		require.NotContains(t, fmt.Sprint(fields), "/new")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := storage_mocks.NewMockRepository(ctrl)
+	ctx := context.TODO()
+	created := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	mr.EXPECT().Get(ctx, "open").Return(storage.StoredURL{ShortID: "open", OriginalURL: "https://ya.ru", CreatedAt: created}, nil)
+	mr.EXPECT().Get(ctx, "locked").Return(storage.StoredURL{ShortID: "locked", OriginalURL: "https://ya.ru", PasswordHash: "hash"}, nil)
+	svc := NewURLService(NewShortGenerator(), "http://localhost", mr)
+
+	link, err := svc.Preview(ctx, "open", TargetParams{})
+	require.NoError(t, err)
+	require.Equal(t, "https://ya.ru", link.OriginalURL)
+	require.Equal(t, "http://localhost/open", link.ShortURL)
+	require.Equal(t, &created, link.CreatedAt)
+	_, err = svc.Preview(ctx, "locked", TargetParams{})
+	require.ErrorIs(t, err, ErrPasswordRequired)
+}
+
+func TestPreviewCreatedAtFromRepository(t *testing.T) {
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	fileRepo, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	svc := NewURLService(NewShortGenerator(), "http://localhost", fileRepo)
+	short, err := svc.Shorten(ctx, "https://ya.ru", 1, ShortenOptions{Alias: "dated"})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost/dated", short)
+	stored, err := fileRepo.Get(ctx, "dated")
+	require.NoError(t, err)
+	require.False(t, stored.CreatedAt.IsZero())
+
+	reopened, err := storage.NewFileRepository(path, storage.NewInMemoryRepository())
+	require.NoError(t, err)
+	for _, repo := range []storage.Repository{fileRepo, reopened} {
+		link, err := NewURLService(NewShortGenerator(), "http://localhost", repo).Preview(ctx, "dated", TargetParams{})
+		require.NoError(t, err)
+		require.NotNil(t, link.CreatedAt)
+		require.True(t, stored.CreatedAt.Equal(*link.CreatedAt))
+	}
+}
@@ -2,29 +2,82 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/cmrd-a/shortener/internal/config"
 )
 
 // Repository defines the interface for URL storage operations.
+//
+// FindOriginals returns the short IDs of the given originals that are already stored within
+// the dedup scope of the user, keyed by original; it finds nothing if deduplication is off.
+//
+// GetUserURLs returns a page of the live URLs of a user that match the query, see URLQuery. SetTags replaces the tags
+// of a URL owned by the user and returns ErrURLNotFound for missing, deleted and foreign URLs.
+// GetUserTags counts the tags of the live URLs of a user, most used first.
+// ExportUserURLs streams the live URLs of a user in the order they were created, with their click
+// counts, without loading them all at once; the sequence ends after the first error.
+//
+// UpdateURL loads the URL owned by userID, lets apply change it, checks the new original
+// against the dedup scope and stores the result together with a new Revision, all atomically.
+// Revision 1, the state at creation, is recorded on the first change. GetRevisions returns
+// the revisions in order, or just the current state as revision 1 if the URL was never changed.
+// Both return ErrURLNotFound for missing, deleted and foreign URLs.
+//
+// MarkDeletedUserURLs moves URLs to the trash, recording when they were deleted, and returns the
+// outcome of each request in the order of the requests. GetDeletedUserURLs
+// lists the trash of a user, most recently deleted first. RestoreUserURLs takes the given URLs of
+// the user out of the trash if they were deleted at or after since and returns the restored short IDs.
+//
+// PurgeURLs physically removes the URLs deleted before deletedBefore and those expired before
+// expiredBefore, together with their clicks and revisions. URLs deleted before deletion times were
+// recorded are treated as deleted long ago.
 type Repository interface {
-	Get(context.Context, string) (string, error)
-	Add(context.Context, string, string, int64) error
+	Get(context.Context, string) (StoredURL, error)
+	ConsumeClick(context.Context, string) (int64, error)
+	Add(context.Context, StoredURL) error
 	AddBatch(context.Context, int64, ...StoredURL) error
+	FindOriginals(ctx context.Context, userID int64, originals ...string) (map[string]string, error)
 	Ping(context.Context) error
-	GetUserURLs(context.Context, int64) ([]StoredURL, error)
-	MarkDeletedUserURLs(context.Context, ...URLForDelete)
+	GetUserURLs(ctx context.Context, userID int64, query URLQuery) ([]StoredURL, error)
+	SetTags(ctx context.Context, userID int64, short string, tags []string) (StoredURL, error)
+	GetUserTags(ctx context.Context, userID int64) ([]TagCount, error)
+	ExportUserURLs(ctx context.Context, userID int64) URLExportSeq
+	MarkDeletedUserURLs(context.Context, ...URLForDelete) ([]DeletionOutcome, error)
+	GetDeletedUserURLs(ctx context.Context, userID int64) ([]StoredURL, error)
+	RestoreUserURLs(ctx context.Context, userID int64, since time.Time, shorts ...string) ([]string, error)
+	PurgeURLs(ctx context.Context, deletedBefore, expiredBefore time.Time) (PurgeCounts, error)
+	MarkExpiredURLs(context.Context, time.Time) (int64, error)
+	AddClicks(context.Context, ...ClickEvent) error
+	GetClickStats(ctx context.Context, userID int64, short string) (ClickStats, error)
+	UpdateURL(ctx context.Context, userID int64, short string, apply func(*StoredURL) error) (StoredURL, error)
+	GetRevisions(ctx context.Context, userID int64, short string) ([]Revision, error)
+}
+
+// DeletionQueue persists accepted deletion requests until they are applied, so that they survive a crash.
+// PendingDeletions returns the requests pushed and not yet removed, in the order they were pushed.
+// RemoveDeletions removes the given requests once they are applied; implementations may also remove
+// other pending copies of them, since applying a deletion twice changes nothing.
+type DeletionQueue interface {
+	PushDeletions(context.Context, ...URLForDelete) error
+	PendingDeletions(context.Context) ([]URLForDelete, error)
+	RemoveDeletions(context.Context, ...URLForDelete) error
 }
 
 // MakeRepository creates a Repository instance based on the provided configuration.
 // It returns a PostgreSQL repository if DatabaseDSN is provided,
 // a file-backed repository if FileStoragePath is provided,
 // or an in-memory repository as the default fallback.
+// All of them deduplicate original URLs within cfg.DedupScope.
 func MakeRepository(ctx context.Context, cfg *config.Config) (Repository, error) {
+	dedup, err := ParseDedupScope(cfg.DedupScope)
+	if err != nil {
+		return nil, err
+	}
 	if cfg.DatabaseDSN != "" {
-		return NewPgRepository(ctx, cfg.DatabaseDSN)
+		return NewPgRepository(ctx, cfg.DatabaseDSN, WithDedupScope(dedup))
 	}
-	inMemoryRepo := NewInMemoryRepository()
+	inMemoryRepo := NewInMemoryRepository(WithDedupScope(dedup))
 	if cfg.FileStoragePath != "" {
 		return NewFileRepository(cfg.FileStoragePath, inMemoryRepo)
 	}
@@ -0,0 +1,51 @@
+package storage
+
+import "fmt"
+
+// DedupScope defines which stored URLs an original URL is compared against when a link is added.
+// Expired URLs are never compared against, so an original can be shortened again once its link expired.
+type DedupScope string
+
+// Supported deduplication scopes.
+const (
+	// DedupGlobal rejects an original URL shortened by any user.
+	DedupGlobal DedupScope = "global"
+	// DedupUser rejects an original URL only if the same user has already shortened it.
+	DedupUser DedupScope = "user"
+	// DedupOff never rejects an original URL, every request gets a new short link.
+	DedupOff DedupScope = "off"
+)
+
+// ParseDedupScope converts a configuration value to a DedupScope.
+// An empty value means DedupGlobal.
+func ParseDedupScope(s string) (DedupScope, error) {
+	switch scope := DedupScope(s); scope {
+	case DedupGlobal, DedupUser, DedupOff:
+		return scope, nil
+	case "":
+		return DedupGlobal, nil
+	}
+	return "", fmt.Errorf("unknown dedup scope %q", s)
+}
+
+// Option configures optional repository settings.
+type Option func(*options)
+
+type options struct {
+	dedup DedupScope
+}
+
+// WithDedupScope sets the deduplication scope of original URLs.
+func WithDedupScope(scope DedupScope) Option {
+	return func(o *options) {
+		o.dedup = scope
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{dedup: DedupGlobal}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -22,5 +22,31 @@ func NewOriginalExistError(short string) error {
 	}
 }
 
+// ErrShortExist represents an error when trying to add a URL under a short ID that is already occupied.
+type ErrShortExist struct {
+	Short string
+}
+
+// Error returns a formatted error message indicating the short ID is already taken.
+func (se *ErrShortExist) Error() string {
+	return fmt.Sprintf("short is already taken:%s", se.Short)
+}
+
+// NewShortExistError creates a new ErrShortExist error with the provided short ID.
+func NewShortExistError(short string) error {
+	return &ErrShortExist{
+		Short: short,
+	}
+}
+
 // ErrURLIsDeleted is returned when attempting to access a URL that has been marked as deleted.
 var ErrURLIsDeleted = errors.New("url is deleted")
+
+// ErrURLIsExpired is returned when attempting to access a URL whose expiry time has passed.
+var ErrURLIsExpired = errors.New("url is expired")
+
+// ErrURLClicksExhausted is returned when attempting to access a click-limited URL that has no clicks left.
+var ErrURLClicksExhausted = errors.New("url has no clicks left")
+
+// ErrURLNotFound is returned when a URL does not exist or is not visible to the caller.
+var ErrURLNotFound = errors.New("url not found")
@@ -1,23 +1,46 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // FileRepository implements the Repository interface using file-based persistence with in-memory caching.
+// Click events are appended to a sibling file with the ".clicks" suffix,
+// and revisions are kept in a sibling file with the ".revisions" suffix.
+// It implements DeletionQueue with a sibling file with the ".deletions" suffix.
+//
+// Appends and rewrites of the files are serialized by filesMu. A rewrite takes its snapshot
+// of the cache while holding it and replaces the file atomically, so that records appended
+// concurrently end up either in the snapshot or after it in the new file.
+//
+// Clicks taken from click-limited URLs are not written on every redirect: the URLs are
+// collected in consumed and appended once per AddClicks call, that is once per click flush.
 type FileRepository struct {
-	path  string
-	cache *InMemoryRepository
+	path        string
+	cache       *InMemoryRepository
+	consumed    map[string]struct{}
+	filesMu     *sync.Mutex
+	deletionsMu *sync.Mutex
 }
 
 // NewFileRepository creates a new FileRepository instance that persists data to a file while using an in-memory cache for fast access.
 func NewFileRepository(path string, cache *InMemoryRepository) (*FileRepository, error) {
-	r := &FileRepository{path, cache}
+	r := &FileRepository{
+		path:        path,
+		cache:       cache,
+		consumed:    make(map[string]struct{}),
+		filesMu:     &sync.Mutex{},
+		deletionsMu: &sync.Mutex{},
+	}
 	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
 		return r, nil
 	}
@@ -26,27 +49,87 @@ func NewFileRepository(path string, cache *InMemoryRepository) (*FileRepository,
 		return nil, err
 	}
 	for str := range strings.SplitSeq(string(data), "\n") {
+		if str == "" {
+			continue
+		}
 		s := StoredURL{}
 		err := s.UnmarshalJSON([]byte(str))
 		if err != nil {
 			return nil, err
 		}
-		err = r.cache.Add(context.TODO(), s.ShortID, s.OriginalURL, s.UserID)
+		r.cache.load(s)
+	}
+	err = r.loadClicks()
+	if err != nil {
+		return nil, err
+	}
+	err = r.loadRevisions()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// clicksPath returns the path of the file holding click events.
+func (r FileRepository) clicksPath() string {
+	return r.path + ".clicks"
+}
+
+// loadClicks reads the click events file into cache.
+func (r FileRepository) loadClicks() error {
+	data, err := os.ReadFile(r.clicksPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	events := make([]ClickEvent, 0)
+	for str := range strings.SplitSeq(string(data), "\n") {
+		if str == "" {
+			continue
+		}
+		e := ClickEvent{}
+		err := e.UnmarshalJSON([]byte(str))
 		if err != nil {
-			return nil, err
+			return err
 		}
+		events = append(events, e)
 	}
-	return r, nil
+	return r.cache.AddClicks(context.Background(), events...)
 }
 
-// Get retrieves the original URL for a given short URL identifier from the cache.
-func (r FileRepository) Get(ctx context.Context, short string) (string, error) {
+// Get retrieves the URL record for a given short URL identifier from the cache.
+func (r FileRepository) Get(ctx context.Context, short string) (StoredURL, error) {
 	return r.cache.Get(ctx, short)
 }
 
+// ConsumeClick takes one click from a click-limited URL in cache. The updated record is appended
+// to the file with the next click events, or right away once the URL has no clicks left.
+// The later record replaces the earlier one when the file is loaded.
+func (r FileRepository) ConsumeClick(ctx context.Context, short string) (int64, error) {
+	left, err := r.cache.ConsumeClick(ctx, short)
+	if err != nil {
+		return 0, err
+	}
+	if left == 0 {
+		url, _ := r.cache.lookup(short)
+		return left, r.appendURLs(url)
+	}
+	r.filesMu.Lock()
+	r.consumed[short] = struct{}{}
+	r.filesMu.Unlock()
+	return left, nil
+}
+
 // Add stores a new URL mapping both in cache and persists it to the file.
-func (r FileRepository) Add(ctx context.Context, short, original string, userID int64) error {
-	return r.AddBatch(ctx, userID, StoredURL{ShortID: short, OriginalURL: original, UserID: userID})
+func (r FileRepository) Add(ctx context.Context, url StoredURL) error {
+	err := r.cache.Add(ctx, url)
+	if err != nil {
+		return err
+	}
+	stored, _ := r.cache.lookup(url.ShortID)
+	return r.appendURLs(stored)
 }
 
 // AddBatch stores multiple URL mappings both in cache and appends them to the file.
@@ -55,11 +138,54 @@ func (r FileRepository) AddBatch(ctx context.Context, userID int64, batch ...Sto
 	if err != nil {
 		return err
 	}
-	file, _ := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	stored := make([]StoredURL, len(batch))
+	for i, url := range batch {
+		stored[i], _ = r.cache.lookup(url.ShortID)
+	}
+	return r.appendURLs(stored...)
+}
+
+// FindOriginals looks for the originals in the cache.
+func (r FileRepository) FindOriginals(ctx context.Context, userID int64, originals ...string) (map[string]string, error) {
+	return r.cache.FindOriginals(ctx, userID, originals...)
+}
+
+// appendURLs appends the URLs to the end of the file.
+func (r FileRepository) appendURLs(urls ...StoredURL) error {
+	r.filesMu.Lock()
+	defer r.filesMu.Unlock()
+	return r.writeURLs(urls...)
+}
+
+// flushConsumed appends the records of the URLs whose clicks were taken since the last flush.
+// The caller must hold filesMu.
+func (r FileRepository) flushConsumed() error {
+	if len(r.consumed) == 0 {
+		return nil
+	}
+	urls := make([]StoredURL, 0, len(r.consumed))
+	for short := range r.consumed {
+		if url, ok := r.cache.lookup(short); ok {
+			urls = append(urls, url)
+		}
+	}
+	if err := r.writeURLs(urls...); err != nil {
+		return err
+	}
+	clear(r.consumed)
+	return nil
+}
+
+// writeURLs appends the URLs to the end of the file. The caller must hold filesMu.
+func (r FileRepository) writeURLs(urls ...StoredURL) error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
 	var result []byte
-	for _, url := range batch {
+	for _, url := range urls {
 		data, err := json.Marshal(url)
 		if err != nil {
 			return err
@@ -75,60 +201,344 @@ func (r FileRepository) AddBatch(ctx context.Context, userID int64, batch ...Sto
 	return nil
 }
 
+// AddClicks stores click events in cache and appends them to the clicks file,
+// after the records of the click-limited URLs followed since the last call.
+func (r FileRepository) AddClicks(ctx context.Context, events ...ClickEvent) error {
+	err := r.cache.AddClicks(ctx, events...)
+	if err != nil {
+		return err
+	}
+	r.filesMu.Lock()
+	defer r.filesMu.Unlock()
+	if err := r.flushConsumed(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.clicksPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var result []byte
+	for _, event := range events {
+		data, err := event.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		result = append(result, data...)
+		result = append(result, '\n')
+	}
+	_, err = file.Write(result)
+	return err
+}
+
+// rewriteClicks replaces the clicks file contents with all click events held in cache.
+func (r FileRepository) rewriteClicks() error {
+	r.filesMu.Lock()
+	defer r.filesMu.Unlock()
+	var result []byte
+	for _, event := range r.cache.allClicks() {
+		data, err := event.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		result = append(result, data...)
+		result = append(result, '\n')
+	}
+	return replaceFile(r.clicksPath(), result)
+}
+
+// GetClickStats aggregates the clicks of a URL owned by the user from the cache.
+func (r FileRepository) GetClickStats(ctx context.Context, userID int64, short string) (ClickStats, error) {
+	return r.cache.GetClickStats(ctx, userID, short)
+}
+
+// revisionsPath returns the path of the file holding revisions.
+func (r FileRepository) revisionsPath() string {
+	return r.path + ".revisions"
+}
+
+// loadRevisions reads the revisions file into cache.
+func (r FileRepository) loadRevisions() error {
+	data, err := os.ReadFile(r.revisionsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	revisions := make([]Revision, 0)
+	for str := range strings.SplitSeq(string(data), "\n") {
+		if str == "" {
+			continue
+		}
+		rev := Revision{}
+		err := rev.UnmarshalJSON([]byte(str))
+		if err != nil {
+			return err
+		}
+		revisions = append(revisions, rev)
+	}
+	slices.SortFunc(revisions, func(a, b Revision) int {
+		return cmp.Compare(a.Number, b.Number)
+	})
+	r.cache.loadRevisions(revisions...)
+	return nil
+}
+
+// UpdateURL changes a URL owned by the user in cache, appends the updated record to the file
+// and rewrites the revisions file.
+func (r FileRepository) UpdateURL(ctx context.Context, userID int64, short string, apply func(*StoredURL) error) (StoredURL, error) {
+	updated, err := r.cache.UpdateURL(ctx, userID, short, apply)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	err = r.appendURLs(updated)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	return updated, r.rewriteRevisions()
+}
+
+// GetRevisions returns the revisions of a URL owned by the user from the cache.
+func (r FileRepository) GetRevisions(ctx context.Context, userID int64, short string) ([]Revision, error) {
+	return r.cache.GetRevisions(ctx, userID, short)
+}
+
+// rewriteRevisions replaces the revisions file contents with all revisions held in cache.
+func (r FileRepository) rewriteRevisions() error {
+	r.filesMu.Lock()
+	defer r.filesMu.Unlock()
+	var result []byte
+	for _, rev := range r.cache.allRevisions() {
+		data, err := rev.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		result = append(result, data...)
+		result = append(result, '\n')
+	}
+	return replaceFile(r.revisionsPath(), result)
+}
+
 // Ping checks the health of the repository (always returns nil for file storage).
 func (r FileRepository) Ping(ctx context.Context) error {
 	return nil
 }
 
-// GetUserURLs retrieves all URLs created by a specific user from the cache.
-func (r FileRepository) GetUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
-	return r.cache.GetUserURLs(ctx, userID)
+// GetUserURLs retrieves the URLs created by a specific user that match the query from the cache.
+func (r FileRepository) GetUserURLs(ctx context.Context, userID int64, query URLQuery) ([]StoredURL, error) {
+	return r.cache.GetUserURLs(ctx, userID, query)
+}
+
+// SetTags replaces the tags of a URL in cache and appends the updated record to the file.
+func (r FileRepository) SetTags(ctx context.Context, userID int64, short string, tags []string) (StoredURL, error) {
+	updated, err := r.cache.SetTags(ctx, userID, short, tags)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	return updated, r.appendURLs(updated)
+}
+
+// ExportUserURLs streams the URLs of a user from the cache.
+func (r FileRepository) ExportUserURLs(ctx context.Context, userID int64) URLExportSeq {
+	return r.cache.ExportUserURLs(ctx, userID)
+}
+
+// GetUserTags counts the tags of the URLs of a user from the cache.
+func (r FileRepository) GetUserTags(ctx context.Context, userID int64) ([]TagCount, error) {
+	return r.cache.GetUserTags(ctx, userID)
 }
 
 // MarkDeletedUserURLs marks the specified URLs as deleted in cache and rewrites the entire file.
-func (r FileRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) {
-	r.cache.MarkDeletedUserURLs(ctx, urls...)
+func (r FileRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) ([]DeletionOutcome, error) {
+	outcomes, err := r.cache.MarkDeletedUserURLs(ctx, urls...)
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, r.rewrite()
+}
 
-	file, _ := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-	defer file.Close()
+// GetDeletedUserURLs retrieves the deleted URLs of a user from the cache.
+func (r FileRepository) GetDeletedUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
+	return r.cache.GetDeletedUserURLs(ctx, userID)
+}
+
+// RestoreUserURLs restores the URLs in cache and appends the restored records to the file.
+func (r FileRepository) RestoreUserURLs(ctx context.Context, userID int64, since time.Time, shorts ...string) ([]string, error) {
+	restored, err := r.cache.RestoreUserURLs(ctx, userID, since, shorts...)
+	if err != nil || len(restored) == 0 {
+		return restored, err
+	}
+	urls := make([]StoredURL, len(restored))
+	for i, short := range restored {
+		urls[i], _ = r.cache.lookup(short)
+	}
+	return restored, r.appendURLs(urls...)
+}
+
+// PurgeURLs removes URLs from cache and compacts the file and its sibling files if any were removed.
+func (r FileRepository) PurgeURLs(ctx context.Context, deletedBefore, expiredBefore time.Time) (PurgeCounts, error) {
+	counts, err := r.cache.PurgeURLs(ctx, deletedBefore, expiredBefore)
+	if err != nil || counts.Deleted+counts.Expired == 0 {
+		return counts, err
+	}
+	if err := r.rewrite(); err != nil {
+		return counts, err
+	}
+	if err := r.rewriteClicks(); err != nil {
+		return counts, err
+	}
+	return counts, r.rewriteRevisions()
+}
+
+// MarkExpiredURLs flags expired URLs in cache and rewrites the file if any of them changed.
+func (r FileRepository) MarkExpiredURLs(ctx context.Context, now time.Time) (int64, error) {
+	count, err := r.cache.MarkExpiredURLs(ctx, now)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, r.rewrite()
+}
+
+// rewrite replaces the file contents with all URLs currently held in cache.
+func (r FileRepository) rewrite() error {
+	r.filesMu.Lock()
+	defer r.filesMu.Unlock()
+
+	r.cache.mu.Lock()
 	all := r.cache.GetAll()
 	var result []byte
 	for _, url := range all {
 		data, err := json.Marshal(url)
 		if err != nil {
-			fmt.Printf("error while marshalling %v", err)
+			r.cache.mu.Unlock()
+			return fmt.Errorf("error marshalling URL data: %v", err)
 		}
-		data = append(data, '\n')
 		result = append(result, data...)
+		result = append(result, '\n')
 	}
+	r.cache.mu.Unlock()
 
-	_, err := file.Write(result)
+	if err := replaceFile(r.path, result); err != nil {
+		return err
+	}
+	clear(r.consumed)
+	return nil
+}
+
+// replaceFile writes data to a temporary file next to path and renames it over path,
+// so that the file is never seen truncated. The caller must hold filesMu.
+func replaceFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		fmt.Printf("error while writing file %v", err)
+		os.Remove(tmp)
+		return err
 	}
+	return os.Rename(tmp, path)
+}
 
+// deletionsPath returns the path of the file holding pending deletion requests.
+func (r FileRepository) deletionsPath() string {
+	return r.path + ".deletions"
 }
 
-// Close closes the FileRepository by ensuring all data is flushed to disk.
-// This method saves all current data to the file during graceful shutdown.
-func (r *FileRepository) Close() error {
-	// Ensure all data is written to file before closing
-	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+// PushDeletions appends the deletion requests to the deletions file and syncs it to disk.
+func (r FileRepository) PushDeletions(ctx context.Context, urls ...URLForDelete) error {
+	r.deletionsMu.Lock()
+	defer r.deletionsMu.Unlock()
+	file, err := os.OpenFile(r.deletionsPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	all := r.cache.GetAll()
 	var result []byte
-	for _, url := range all {
-		data, err := json.Marshal(url)
+	for _, url := range urls {
+		data, err := url.MarshalJSON()
 		if err != nil {
-			return fmt.Errorf("error marshalling URL data: %v", err)
+			return err
 		}
 		result = append(result, data...)
 		result = append(result, '\n')
 	}
-	_, err = file.Write(result)
-	return err
+	if _, err = file.Write(result); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// PendingDeletions reads the deletion requests from the deletions file.
+func (r FileRepository) PendingDeletions(ctx context.Context) ([]URLForDelete, error) {
+	r.deletionsMu.Lock()
+	defer r.deletionsMu.Unlock()
+	return r.readDeletions()
+}
+
+// RemoveDeletions rewrites the deletions file without the given deletion requests.
+func (r FileRepository) RemoveDeletions(ctx context.Context, urls ...URLForDelete) error {
+	r.deletionsMu.Lock()
+	defer r.deletionsMu.Unlock()
+	pending, err := r.readDeletions()
+	if err != nil {
+		return err
+	}
+	removed := make(map[URLForDelete]int, len(urls))
+	for _, url := range urls {
+		removed[url]++
+	}
+	var result []byte
+	for _, url := range pending {
+		if removed[url] > 0 {
+			removed[url]--
+			continue
+		}
+		data, err := url.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		result = append(result, data...)
+		result = append(result, '\n')
+	}
+	return os.WriteFile(r.deletionsPath(), result, 0666)
+}
+
+// readDeletions reads the deletions file. The caller must hold deletionsMu.
+func (r FileRepository) readDeletions() ([]URLForDelete, error) {
+	data, err := os.ReadFile(r.deletionsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var urls []URLForDelete
+	for str := range strings.SplitSeq(string(data), "\n") {
+		if str == "" {
+			continue
+		}
+		url := URLForDelete{}
+		if err := url.UnmarshalJSON([]byte(str)); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+// Close closes the FileRepository by ensuring all data is flushed to disk.
+// This method saves all current data to the file during graceful shutdown.
+func (r *FileRepository) Close() error {
+	return r.rewrite()
 }
@@ -1,106 +1,489 @@
 package storage
 
 import (
+	"cmp"
 	"context"
-	"errors"
+	"slices"
 	"sync"
+	"time"
 )
 
 // InMemoryRepository implements the Repository interface using in-memory maps for storage.
 type InMemoryRepository struct {
 	store     map[string]StoredURL
 	userIndex map[int64][]string
+	clicks    map[string][]ClickEvent
+	revisions map[string][]Revision
+	search    *searchIndex
+	dedup     DedupScope
 	mu        *sync.Mutex
 }
 
 // NewInMemoryRepository creates a new InMemoryRepository instance with initialized storage maps.
-func NewInMemoryRepository() *InMemoryRepository {
+// Original URLs are deduplicated globally unless WithDedupScope says otherwise.
+func NewInMemoryRepository(opts ...Option) *InMemoryRepository {
+	o := newOptions(opts)
 	return &InMemoryRepository{
 		store:     make(map[string]StoredURL),
 		userIndex: make(map[int64][]string),
+		clicks:    make(map[string][]ClickEvent),
+		revisions: make(map[string][]Revision),
+		search:    newSearchIndex(),
+		dedup:     o.dedup,
 		mu:        &sync.Mutex{},
 	}
 }
 
-// Get retrieves the original URL for a given short URL identifier.
-func (r InMemoryRepository) Get(ctx context.Context, short string) (string, error) {
+// Get retrieves the URL record for a given short URL identifier.
+func (r InMemoryRepository) Get(ctx context.Context, short string) (StoredURL, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	storedURL, ok := r.store[short]
 	if !ok {
-		return "", errors.New("url not found")
+		return StoredURL{}, ErrURLNotFound
 	}
 	if storedURL.IsDeleted {
-		return "", ErrURLIsDeleted
+		return StoredURL{}, ErrURLIsDeleted
 	}
-	return storedURL.OriginalURL, nil
+	if storedURL.Expired(time.Now()) {
+		return StoredURL{}, ErrURLIsExpired
+	}
+	if storedURL.ClicksLeft != nil && *storedURL.ClicksLeft <= 0 {
+		return StoredURL{}, ErrURLClicksExhausted
+	}
+	return storedURL, nil
 }
 
-func (r InMemoryRepository) checkOriginalExist(original string) (string, bool) {
+// ConsumeClick atomically takes one click from a click-limited URL and returns how many are left.
+func (r InMemoryRepository) ConsumeClick(ctx context.Context, short string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	storedURL, ok := r.store[short]
+	if !ok || storedURL.ClicksLeft == nil || *storedURL.ClicksLeft <= 0 {
+		return 0, ErrURLClicksExhausted
+	}
+	left := *storedURL.ClicksLeft - 1
+	storedURL.ClicksLeft = &left
+	r.store[short] = storedURL
+	return left, nil
+}
+
+// checkOriginalExist looks for the original URL within the dedup scope of the user.
+// Expired URLs are skipped, so that an original can be shortened again once its link expired.
+func (r InMemoryRepository) checkOriginalExist(original string, userID int64) (string, bool) {
+	if r.dedup == DedupOff {
+		return "", false
+	}
+	now := time.Now()
 	for key, value := range r.store {
-		if value.OriginalURL == original {
+		if value.OriginalURL == original && (r.dedup == DedupGlobal || value.UserID == userID) && !value.Expired(now) {
 			return key, true
 		}
 	}
 	return "", false
 }
 
+// FindOriginals looks for the originals within the dedup scope of the user in a single pass.
+// Like checkOriginalExist it skips expired URLs.
+func (r InMemoryRepository) FindOriginals(ctx context.Context, userID int64, originals ...string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := make(map[string]string)
+	if r.dedup == DedupOff || len(originals) == 0 {
+		return found, nil
+	}
+	wanted := make(map[string]struct{}, len(originals))
+	for _, original := range originals {
+		wanted[original] = struct{}{}
+	}
+	now := time.Now()
+	for key, value := range r.store {
+		if _, ok := wanted[value.OriginalURL]; ok && (r.dedup == DedupGlobal || value.UserID == userID) && !value.Expired(now) {
+			found[value.OriginalURL] = key
+		}
+	}
+	return found, nil
+}
+
 // Add stores a new URL mapping in the repository.
-func (r InMemoryRepository) Add(ctx context.Context, short, original string, userID int64) error {
+// It returns ErrOriginalExist if the original is already stored within the dedup scope
+// and ErrShortExist if the short ID is occupied.
+func (r InMemoryRepository) Add(ctx context.Context, url StoredURL) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if oldShort, ok := r.checkOriginalExist(original); ok {
+	if oldShort, ok := r.checkOriginalExist(url.OriginalURL, url.UserID); ok {
 		return NewOriginalExistError(oldShort)
 	}
-	r.store[short] = StoredURL{ShortID: short, OriginalURL: original, UserID: userID}
-	r.userIndex[userID] = append(r.userIndex[userID], short)
+	if _, ok := r.store[url.ShortID]; ok {
+		return NewShortExistError(url.ShortID)
+	}
+	url.CreatedAt = time.Now()
+	r.put(url)
 	return nil
 }
 
 // AddBatch stores multiple URL mappings in a single operation.
+// Nothing is stored if any of the short IDs is already occupied or if any of the originals
+// is already stored within the dedup scope of the user or repeated in the batch.
 func (r InMemoryRepository) AddBatch(ctx context.Context, userID int64, batch ...StoredURL) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	seen := make(map[string]struct{}, len(batch))
+	originals := make(map[string]string, len(batch))
+	for _, url := range batch {
+		if oldShort, ok := r.checkOriginalExist(url.OriginalURL, userID); ok {
+			return NewOriginalExistError(oldShort)
+		}
+		if oldShort, ok := originals[url.OriginalURL]; ok && r.dedup != DedupOff {
+			return NewOriginalExistError(oldShort)
+		}
+		originals[url.OriginalURL] = url.ShortID
+	}
+	for _, url := range batch {
+		_, inBatch := seen[url.ShortID]
+		if _, ok := r.store[url.ShortID]; ok || inBatch {
+			return NewShortExistError(url.ShortID)
+		}
+		seen[url.ShortID] = struct{}{}
+	}
+	now := time.Now()
 	for _, url := range batch {
-		r.store[url.ShortID] = StoredURL{ShortID: url.ShortID, OriginalURL: url.OriginalURL, UserID: userID}
+		url.UserID = userID
+		url.CreatedAt = now
+		r.put(url)
 	}
 	return nil
 }
 
+// put stores the URL as is, replacing any previous record with the same short ID.
+// The caller must hold the mutex.
+func (r InMemoryRepository) put(url StoredURL) {
+	if _, ok := r.store[url.ShortID]; !ok {
+		r.userIndex[url.UserID] = append(r.userIndex[url.UserID], url.ShortID)
+	}
+	r.store[url.ShortID] = url
+	r.search.put(url)
+}
+
+// lookup returns the URL record as is, including deleted and expired ones.
+func (r InMemoryRepository) lookup(short string) (StoredURL, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.store[short]
+	return url, ok
+}
+
+// load restores a previously persisted URL record without any duplicate checks.
+func (r InMemoryRepository) load(url StoredURL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(url)
+}
+
 // Ping checks the health of the repository (always returns nil for in-memory storage).
 func (r InMemoryRepository) Ping(ctx context.Context) error {
 	return nil
 }
 
-// GetUserURLs retrieves all non-deleted URLs created by a specific user.
-func (r InMemoryRepository) GetUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
+// GetUserURLs retrieves the non-deleted URLs created by a specific user that match the query.
+func (r InMemoryRepository) GetUserURLs(ctx context.Context, userID int64, query URLQuery) ([]StoredURL, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	shortIDs, exists := r.userIndex[userID]
 	if !exists {
 		return []StoredURL{}, nil
 	}
+	if found, ok := r.search.find(query.Search); ok && len(found) < len(shortIDs) {
+		shortIDs = found
+	}
 
 	urls := make([]StoredURL, 0, len(shortIDs))
 	for _, shortID := range shortIDs {
-		if storedURL, ok := r.store[shortID]; ok && !storedURL.IsDeleted {
+		storedURL, ok := r.store[shortID]
+		if ok && storedURL.UserID == userID && !storedURL.IsDeleted && query.match(storedURL) {
 			urls = append(urls, storedURL)
 		}
 	}
-	return urls, nil
+	return query.page(urls), nil
+}
+
+// SetTags replaces the tags of a URL owned by the user.
+func (r InMemoryRepository) SetTags(ctx context.Context, userID int64, short string, tags []string) (StoredURL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.store[short]
+	if !ok || url.IsDeleted || url.UserID != userID {
+		return StoredURL{}, ErrURLNotFound
+	}
+	url.Tags = slices.Clone(tags)
+	r.store[short] = url
+	r.search.put(url)
+	return url, nil
+}
+
+// GetUserTags counts the tags of the non-deleted URLs of a user, most used first.
+func (r InMemoryRepository) GetUserTags(ctx context.Context, userID int64) ([]TagCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int64)
+	for _, shortID := range r.userIndex[userID] {
+		if storedURL, ok := r.store[shortID]; ok && !storedURL.IsDeleted {
+			for _, tag := range storedURL.Tags {
+				counts[tag]++
+			}
+		}
+	}
+	return sortTagCounts(counts), nil
+}
+
+// ExportUserURLs yields the live URLs of a user with their click counts.
+// Each URL is read under the lock separately, so the export does not block writers.
+func (r InMemoryRepository) ExportUserURLs(ctx context.Context, userID int64) URLExportSeq {
+	return func(yield func(URLExport, error) bool) {
+		r.mu.Lock()
+		shortIDs := slices.Clone(r.userIndex[userID])
+		r.mu.Unlock()
+		for _, shortID := range shortIDs {
+			if err := ctx.Err(); err != nil {
+				yield(URLExport{}, err)
+				return
+			}
+			r.mu.Lock()
+			url, ok := r.store[shortID]
+			export := URLExport{StoredURL: url, Clicks: int64(len(r.clicks[shortID]))}
+			r.mu.Unlock()
+			if !ok || url.IsDeleted || url.UserID != userID {
+				continue
+			}
+			if !yield(export, nil) {
+				return
+			}
+		}
+	}
+}
+
+// sortTagCounts turns tag counts into a list ordered by count, most used first, then by tag.
+func sortTagCounts(counts map[string]int64) []TagCount {
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b TagCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+	return tags
 }
 
-// MarkDeletedUserURLs marks the specified URLs as deleted for the given users.
-func (r InMemoryRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) {
+// MarkDeletedUserURLs marks the specified URLs as deleted for the given users and returns the outcome of each request.
+// URLs already in the trash keep their original deletion time.
+func (r InMemoryRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) ([]DeletionOutcome, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	for _, url := range urls {
-		if r.store[url.ShortID].UserID == url.UserID {
-			v := r.store[url.ShortID]
+	now := time.Now()
+	outcomes := make([]DeletionOutcome, len(urls))
+	for i, url := range urls {
+		v, ok := r.store[url.ShortID]
+		switch {
+		case !ok:
+			outcomes[i] = OutcomeNotFound
+		case v.UserID != url.UserID:
+			outcomes[i] = OutcomeNotOwned
+		case v.IsDeleted:
+			outcomes[i] = OutcomeAlreadyDeleted
+		default:
 			v.IsDeleted = true
+			v.DeletedAt = &now
 			r.store[url.ShortID] = v
+			outcomes[i] = OutcomeDeleted
 		}
 	}
+	return outcomes, nil
+}
+
+// GetDeletedUserURLs retrieves the deleted URLs of a user, most recently deleted first.
+// URLs without a deletion time come last.
+func (r InMemoryRepository) GetDeletedUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	urls := make([]StoredURL, 0)
+	for _, shortID := range r.userIndex[userID] {
+		if storedURL, ok := r.store[shortID]; ok && storedURL.IsDeleted {
+			urls = append(urls, storedURL)
+		}
+	}
+	slices.SortStableFunc(urls, func(a, b StoredURL) int {
+		switch {
+		case a.DeletedAt == nil && b.DeletedAt == nil:
+			return 0
+		case a.DeletedAt == nil:
+			return 1
+		case b.DeletedAt == nil:
+			return -1
+		}
+		return b.DeletedAt.Compare(*a.DeletedAt)
+	})
+	return urls, nil
+}
+
+// RestoreUserURLs takes the URLs of the user deleted at or after since out of the trash
+// and returns their short IDs.
+func (r InMemoryRepository) RestoreUserURLs(ctx context.Context, userID int64, since time.Time, shorts ...string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restored := make([]string, 0, len(shorts))
+	for _, short := range shorts {
+		v, ok := r.store[short]
+		if !ok || v.UserID != userID || !v.IsDeleted || v.DeletedAt == nil || v.DeletedAt.Before(since) {
+			continue
+		}
+		v.IsDeleted = false
+		v.DeletedAt = nil
+		r.store[short] = v
+		restored = append(restored, short)
+	}
+	return restored, nil
+}
+
+// PurgeURLs removes the URLs deleted before deletedBefore and those expired before expiredBefore
+// together with their clicks and revisions.
+func (r InMemoryRepository) PurgeURLs(ctx context.Context, deletedBefore, expiredBefore time.Time) (PurgeCounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var counts PurgeCounts
+	for short, url := range r.store {
+		switch {
+		case url.IsDeleted && (url.DeletedAt == nil || url.DeletedAt.Before(deletedBefore)):
+			counts.Deleted++
+		case url.ExpiresAt != nil && url.ExpiresAt.Before(expiredBefore):
+			counts.Expired++
+		default:
+			continue
+		}
+		delete(r.store, short)
+		delete(r.clicks, short)
+		delete(r.revisions, short)
+		r.search.remove(short)
+		r.userIndex[url.UserID] = slices.DeleteFunc(r.userIndex[url.UserID], func(s string) bool { return s == short })
+	}
+	return counts, nil
+}
+
+// MarkExpiredURLs flags the URLs whose expiry time is not after now and returns how many were flagged.
+func (r InMemoryRepository) MarkExpiredURLs(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for short, url := range r.store {
+		if !url.IsExpired && url.Expired(now) {
+			url.IsExpired = true
+			r.store[short] = url
+			count++
+		}
+	}
+	return count, nil
+}
+
+// AddClicks stores click events.
+func (r InMemoryRepository) AddClicks(ctx context.Context, events ...ClickEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range events {
+		r.clicks[event.ShortID] = append(r.clicks[event.ShortID], event)
+	}
+	return nil
+}
+
+// GetClickStats aggregates the clicks of a URL owned by the user.
+// It returns ErrURLNotFound if the URL does not exist, is deleted or belongs to another user.
+func (r InMemoryRepository) GetClickStats(ctx context.Context, userID int64, short string) (ClickStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.store[short]
+	if !ok || url.IsDeleted || url.UserID != userID {
+		return ClickStats{}, ErrURLNotFound
+	}
+	stats := NewClickStats()
+	for _, event := range r.clicks[short] {
+		stats.Count(event)
+	}
+	return stats, nil
+}
+
+// UpdateURL changes a URL owned by the user and records the change as a new revision.
+func (r InMemoryRepository) UpdateURL(ctx context.Context, userID int64, short string, apply func(*StoredURL) error) (StoredURL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.store[short]
+	if !ok || url.IsDeleted || url.UserID != userID {
+		return StoredURL{}, ErrURLNotFound
+	}
+	updated := url
+	if err := apply(&updated); err != nil {
+		return StoredURL{}, err
+	}
+	now := time.Now()
+	if updated.OriginalURL != url.OriginalURL || url.Expired(now) && !updated.Expired(now) {
+		if other, ok := r.checkOriginalExist(updated.OriginalURL, userID); ok && other != short {
+			return StoredURL{}, NewOriginalExistError(other)
+		}
+	}
+	revisions := r.revisions[short]
+	if len(revisions) == 0 {
+		revisions = append(revisions, url.Revision(1, url.UserID, url.CreatedAt))
+	}
+	revisions = append(revisions, updated.Revision(int64(len(revisions)+1), userID, now))
+	r.revisions[short] = revisions
+	r.store[short] = updated
+	r.search.put(updated)
+	return updated, nil
+}
+
+// GetRevisions returns the revisions of a URL owned by the user.
+func (r InMemoryRepository) GetRevisions(ctx context.Context, userID int64, short string) ([]Revision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.store[short]
+	if !ok || url.IsDeleted || url.UserID != userID {
+		return nil, ErrURLNotFound
+	}
+	if len(r.revisions[short]) == 0 {
+		return []Revision{url.Revision(1, url.UserID, url.CreatedAt)}, nil
+	}
+	return slices.Clone(r.revisions[short]), nil
+}
+
+// loadRevisions restores previously persisted revisions without any checks.
+func (r InMemoryRepository) loadRevisions(revisions ...Revision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rev := range revisions {
+		r.revisions[rev.ShortID] = append(r.revisions[rev.ShortID], rev)
+	}
+}
+
+// allClicks returns the click events of all URLs.
+func (r InMemoryRepository) allClicks() []ClickEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []ClickEvent
+	for _, events := range r.clicks {
+		all = append(all, events...)
+	}
+	return all
+}
+
+// allRevisions returns the revisions of all URLs.
+func (r InMemoryRepository) allRevisions() []Revision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []Revision
+	for _, revisions := range r.revisions {
+		all = append(all, revisions...)
+	}
+	return all
 }
 
 // GetAll returns all stored URLs (used primarily for testing and debugging).
@@ -2,9 +2,16 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func BenchmarkInMemoryRepositoryGetUserURLs(b *testing.B) {
@@ -22,16 +29,974 @@ func BenchmarkInMemoryRepositoryGetUserURLs(b *testing.B) {
 					for urlID := range urlCount {
 						shortID := fmt.Sprintf("user_%d_url_%d", userID, urlID)
 						originalURL := fmt.Sprintf("https://user%d.example.com/%d", userID, urlID)
-						repo.Add(ctx, shortID, originalURL, int64(userID))
+						repo.Add(ctx, StoredURL{ShortID: shortID, OriginalURL: originalURL, UserID: int64(userID)})
 					}
 				}
 
 				b.ResetTimer()
 				for b.Loop() {
 					userID := int64(rand.Intn(userCount))
-					_, _ = repo.GetUserURLs(ctx, userID)
+					_, _ = repo.GetUserURLs(ctx, userID, URLQuery{})
 				}
 			})
 		}
 	}
 }
+
+func TestInMemoryRepositoryAddShortCollision(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+
+	if err := repo.Add(ctx, StoredURL{ShortID: "abcde", OriginalURL: "https://first.example", UserID: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := repo.Add(ctx, StoredURL{ShortID: "abcde", OriginalURL: "https://second.example", UserID: 2})
+	var shortErr *ErrShortExist
+	if !errors.As(err, &shortErr) || shortErr.Short != "abcde" {
+		t.Fatalf("expected ErrShortExist for abcde, got %v", err)
+	}
+
+	stored, err := repo.Get(ctx, "abcde")
+	if err != nil || stored.OriginalURL != "https://first.example" {
+		t.Fatalf("existing link was changed: %q, %v", stored.OriginalURL, err)
+	}
+}
+
+func TestInMemoryRepositoryAddBatchShortCollision(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+
+	if err := repo.Add(ctx, StoredURL{ShortID: "taken", OriginalURL: "https://first.example", UserID: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := repo.AddBatch(ctx, 2,
+		StoredURL{ShortID: "free", OriginalURL: "https://second.example"},
+		StoredURL{ShortID: "taken", OriginalURL: "https://third.example"},
+	)
+	var shortErr *ErrShortExist
+	if !errors.As(err, &shortErr) || shortErr.Short != "taken" {
+		t.Fatalf("expected ErrShortExist for taken, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "free"); err == nil {
+		t.Fatal("batch must not be stored partially")
+	}
+	urls, _ := repo.GetUserURLs(ctx, 2, URLQuery{})
+	if len(urls) != 0 {
+		t.Fatalf("expected no urls for user 2, got %v", urls)
+	}
+}
+
+func TestInMemoryRepositoryAddBatchDedup(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		scope    DedupScope
+		existing string
+		repeated string
+	}{
+		{DedupGlobal, "theirs", "batch3"},
+		{DedupUser, "", "batch3"},
+		{DedupOff, "", ""},
+	} {
+		repo := NewInMemoryRepository(WithDedupScope(tt.scope))
+		_ = repo.Add(ctx, StoredURL{ShortID: "theirs", OriginalURL: "https://theirs.example", UserID: 2})
+
+		err := repo.AddBatch(ctx, 1,
+			StoredURL{ShortID: "batch1", OriginalURL: "https://new.example"},
+			StoredURL{ShortID: "batch2", OriginalURL: "https://theirs.example"},
+		)
+		checkBatchDedup(t, tt.scope, "existing", err, tt.existing)
+		err = repo.AddBatch(ctx, 3,
+			StoredURL{ShortID: "batch3", OriginalURL: "https://repeated.example"},
+			StoredURL{ShortID: "batch4", OriginalURL: "https://repeated.example"},
+		)
+		checkBatchDedup(t, tt.scope, "repeated", err, tt.repeated)
+	}
+}
+
+func TestFileRepositoryAddBatchDedup(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository(WithDedupScope(DedupUser)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "mine", OriginalURL: "https://mine.example", UserID: 1})
+
+	err = repo.AddBatch(ctx, 1,
+		StoredURL{ShortID: "batch1", OriginalURL: "https://new.example"},
+		StoredURL{ShortID: "batch2", OriginalURL: "https://mine.example"},
+	)
+	checkBatchDedup(t, DedupUser, "existing", err, "mine")
+	if err := repo.AddBatch(ctx, 2, StoredURL{ShortID: "batch2", OriginalURL: "https://mine.example"}); err != nil {
+		t.Fatalf("original of another user must be stored: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository(WithDedupScope(DedupUser)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reopened.Get(ctx, "batch1"); !errors.Is(err, ErrURLNotFound) {
+		t.Fatalf("rejected batch must not be written to the file, got %v", err)
+	}
+	if stored, err := reopened.Get(ctx, "batch2"); err != nil || stored.UserID != 2 {
+		t.Fatalf("expected batch2 of user 2 after reload, got %+v, %v", stored, err)
+	}
+}
+
+// checkBatchDedup checks that a batch failed with ErrOriginalExist for the short ID want,
+// or that it was stored if want is empty.
+func checkBatchDedup(t *testing.T, scope DedupScope, name string, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Errorf("%s: %s original: unexpected error: %v", scope, name, err)
+		}
+		return
+	}
+	var origErr *ErrOriginalExist
+	if !errors.As(err, &origErr) || origErr.Short != want {
+		t.Errorf("%s: %s original: expected ErrOriginalExist for %s, got %v", scope, name, want, err)
+	}
+}
+
+func TestInMemoryRepositoryDedupSkipsExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	past := time.Now().Add(-time.Minute)
+	_ = repo.Add(ctx, StoredURL{ShortID: "old", OriginalURL: "https://again.example", UserID: 1, ExpiresAt: &past})
+
+	found, err := repo.FindOriginals(ctx, 1, "https://again.example")
+	if err != nil || len(found) != 0 {
+		t.Fatalf("expired url must not be found: %v, %v", found, err)
+	}
+	if err := repo.Add(ctx, StoredURL{ShortID: "new", OriginalURL: "https://again.example", UserID: 1}); err != nil {
+		t.Fatalf("original of an expired url must be stored again: %v", err)
+	}
+	err = repo.Add(ctx, StoredURL{ShortID: "third", OriginalURL: "https://again.example", UserID: 1})
+	var origErr *ErrOriginalExist
+	if !errors.As(err, &origErr) || origErr.Short != "new" {
+		t.Fatalf("expected ErrOriginalExist for new, got %v", err)
+	}
+}
+
+func TestInMemoryRepositoryExpiry(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	_ = repo.Add(ctx, StoredURL{ShortID: "old", OriginalURL: "https://old.example", UserID: 1, ExpiresAt: &past})
+	_ = repo.Add(ctx, StoredURL{ShortID: "new", OriginalURL: "https://new.example", UserID: 1, ExpiresAt: &future})
+
+	if _, err := repo.Get(ctx, "old"); !errors.Is(err, ErrURLIsExpired) {
+		t.Fatalf("expected ErrURLIsExpired, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "new"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count, err := repo.MarkExpiredURLs(ctx, time.Now())
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 marked url, got %d, %v", count, err)
+	}
+	if !repo.GetAll()["old"].IsExpired {
+		t.Fatal("expired url is not marked")
+	}
+	count, _ = repo.MarkExpiredURLs(ctx, time.Now())
+	if count != 0 {
+		t.Fatalf("already marked urls must not be counted again, got %d", count)
+	}
+}
+
+func TestInMemoryRepositoryConsumeClick(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	maxClicks := int64(2)
+	_ = repo.Add(ctx, StoredURL{ShortID: "limited", OriginalURL: "https://limited.example", UserID: 1, ClicksLeft: &maxClicks})
+	_ = repo.Add(ctx, StoredURL{ShortID: "unlimited", OriginalURL: "https://unlimited.example", UserID: 1})
+
+	for want := int64(1); want >= 0; want-- {
+		left, err := repo.ConsumeClick(ctx, "limited")
+		if err != nil || left != want {
+			t.Fatalf("expected %d clicks left, got %d, %v", want, left, err)
+		}
+	}
+	if _, err := repo.ConsumeClick(ctx, "limited"); !errors.Is(err, ErrURLClicksExhausted) {
+		t.Fatalf("expected ErrURLClicksExhausted, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "limited"); !errors.Is(err, ErrURLClicksExhausted) {
+		t.Fatalf("expected ErrURLClicksExhausted from Get, got %v", err)
+	}
+	if _, err := repo.ConsumeClick(ctx, "unlimited"); !errors.Is(err, ErrURLClicksExhausted) {
+		t.Fatalf("unlimited urls have no clicks to consume, got %v", err)
+	}
+}
+
+func TestInMemoryRepositoryConsumeClickConcurrent(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	maxClicks := int64(10)
+	_ = repo.Add(ctx, StoredURL{ShortID: "limited", OriginalURL: "https://limited.example", UserID: 1, ClicksLeft: &maxClicks})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.ConsumeClick(ctx, "limited"); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 10 {
+		t.Fatalf("expected exactly 10 successful clicks, got %d", succeeded)
+	}
+}
+
+func TestInMemoryRepositoryClickStats(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	_ = repo.Add(ctx, StoredURL{ShortID: "tracked", OriginalURL: "https://tracked.example", UserID: 1})
+
+	day := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	err := repo.AddClicks(ctx,
+		ClickEvent{ShortID: "tracked", ClickedAt: day, Referrer: "news.example", Browser: "Firefox"},
+		ClickEvent{ShortID: "tracked", ClickedAt: day.Add(time.Hour), Browser: "Chrome"},
+		ClickEvent{ShortID: "tracked", ClickedAt: day.Add(2 * time.Hour), Referrer: "news.example", Browser: "Chrome"},
+		ClickEvent{ShortID: "other", ClickedAt: day, Browser: "Chrome"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats, err := repo.GetClickStats(ctx, 1, "tracked")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Total != 3 {
+		t.Fatalf("expected 3 clicks, got %d", stats.Total)
+	}
+	if stats.ByDay["2026-03-01"] != 1 || stats.ByDay["2026-03-02"] != 2 {
+		t.Fatalf("unexpected by day breakdown: %v", stats.ByDay)
+	}
+	if stats.ByReferrer["news.example"] != 2 || stats.ByReferrer[""] != 1 {
+		t.Fatalf("unexpected by referrer breakdown: %v", stats.ByReferrer)
+	}
+	if stats.ByBrowser["Chrome"] != 2 || stats.ByBrowser["Firefox"] != 1 {
+		t.Fatalf("unexpected by browser breakdown: %v", stats.ByBrowser)
+	}
+
+	if _, err := repo.GetClickStats(ctx, 2, "tracked"); !errors.Is(err, ErrURLNotFound) {
+		t.Fatalf("expected ErrURLNotFound for another user, got %v", err)
+	}
+	if _, err := repo.GetClickStats(ctx, 1, "missing"); !errors.Is(err, ErrURLNotFound) {
+		t.Fatalf("expected ErrURLNotFound for missing url, got %v", err)
+	}
+}
+
+func TestFileRepositoryClicksSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "tracked", OriginalURL: "https://tracked.example", UserID: 1})
+	err = repo.AddClicks(ctx, ClickEvent{ShortID: "tracked", ClickedAt: time.Now(), Browser: "Safari"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := reopened.GetClickStats(ctx, 1, "tracked")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Total != 1 || stats.ByBrowser["Safari"] != 1 {
+		t.Fatalf("unexpected stats after reload: %+v", stats)
+	}
+}
+
+func TestFileRepositoryBatchesConsumedClicks(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clicks := int64(100)
+	_ = repo.Add(ctx, StoredURL{ShortID: "limited", OriginalURL: "https://limited.example", UserID: 1, ClicksLeft: &clicks})
+	_ = repo.Add(ctx, StoredURL{ShortID: "single", OriginalURL: "https://single.example", UserID: 1, ClicksLeft: new(int64)})
+	lines := func() int {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return strings.Count(string(data), "\n")
+	}
+	before := lines()
+
+	for range 10 {
+		if _, err := repo.ConsumeClick(ctx, "limited"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := lines(); got != before {
+		t.Fatalf("clicks must not be written one by one, file grew by %d lines", got-before)
+	}
+	if err := repo.AddClicks(ctx, ClickEvent{ShortID: "limited", ClickedAt: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := lines(); got != before+1 {
+		t.Fatalf("expected one record per flush, file grew by %d lines", got-before)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, err := reopened.Get(ctx, "limited")
+	if err != nil || *stored.ClicksLeft != 90 {
+		t.Fatalf("expected 90 clicks left after reload, got %+v, %v", stored, err)
+	}
+
+	// Taking the last click is written right away.
+	one := int64(1)
+	_ = repo.Add(ctx, StoredURL{ShortID: "last", OriginalURL: "https://last.example", UserID: 1, ClicksLeft: &one})
+	if _, err := repo.ConsumeClick(ctx, "last"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reopened, err = NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reopened.Get(ctx, "last"); !errors.Is(err, ErrURLClicksExhausted) {
+		t.Fatalf("expected ErrURLClicksExhausted after reload, got %v", err)
+	}
+}
+
+func TestInMemoryRepositoryDedupScope(t *testing.T) {
+	ctx := context.Background()
+	original := "https://shared.example"
+	tests := []struct {
+		scope         DedupScope
+		sameUserErr   bool
+		otherUserErr  bool
+		wantUserLinks int
+	}{
+		{scope: DedupGlobal, sameUserErr: true, otherUserErr: true, wantUserLinks: 0},
+		{scope: DedupUser, sameUserErr: true, otherUserErr: false, wantUserLinks: 1},
+		{scope: DedupOff, sameUserErr: false, otherUserErr: false, wantUserLinks: 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			repo := NewInMemoryRepository(WithDedupScope(tt.scope))
+			if err := repo.Add(ctx, StoredURL{ShortID: "first", OriginalURL: original, UserID: 1}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err := repo.Add(ctx, StoredURL{ShortID: "again", OriginalURL: original, UserID: 1})
+			var existErr *ErrOriginalExist
+			if got := errors.As(err, &existErr); got != tt.sameUserErr {
+				t.Fatalf("same user: expected ErrOriginalExist=%v, got %v", tt.sameUserErr, err)
+			}
+			if tt.sameUserErr && existErr.Short != "first" {
+				t.Fatalf("expected existing short 'first', got %s", existErr.Short)
+			}
+
+			err = repo.Add(ctx, StoredURL{ShortID: "other", OriginalURL: original, UserID: 2})
+			if got := errors.As(err, &existErr); got != tt.otherUserErr {
+				t.Fatalf("other user: expected ErrOriginalExist=%v, got %v", tt.otherUserErr, err)
+			}
+			urls, _ := repo.GetUserURLs(ctx, 2, URLQuery{})
+			if len(urls) != tt.wantUserLinks {
+				t.Fatalf("expected %d links of the other user, got %d", tt.wantUserLinks, len(urls))
+			}
+		})
+	}
+}
+
+func TestParseDedupScope(t *testing.T) {
+	for in, want := range map[string]DedupScope{"": DedupGlobal, "global": DedupGlobal, "user": DedupUser, "off": DedupOff} {
+		got, err := ParseDedupScope(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseDedupScope(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDedupScope("tenant"); err == nil {
+		t.Fatal("expected error for unknown scope")
+	}
+}
+
+func TestInMemoryRepositoryUpdateURL(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	_ = repo.Add(ctx, StoredURL{ShortID: "edit", OriginalURL: "https://v1.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "taken", OriginalURL: "https://taken.example", UserID: 2})
+
+	revisions, err := repo.GetRevisions(ctx, 1, "edit")
+	if err != nil || len(revisions) != 1 || revisions[0].OriginalURL != "https://v1.example" {
+		t.Fatalf("expected the current state as revision 1, got %+v, %v", revisions, err)
+	}
+
+	setOriginal := func(original string) func(*StoredURL) error {
+		return func(u *StoredURL) error {
+			u.OriginalURL = original
+			return nil
+		}
+	}
+	if _, err := repo.UpdateURL(ctx, 2, "edit", setOriginal("https://foreign.example")); !errors.Is(err, ErrURLNotFound) {
+		t.Fatalf("expected ErrURLNotFound for another user, got %v", err)
+	}
+	var existErr *ErrOriginalExist
+	if _, err := repo.UpdateURL(ctx, 1, "edit", setOriginal("https://taken.example")); !errors.As(err, &existErr) {
+		t.Fatalf("expected ErrOriginalExist, got %v", err)
+	}
+
+	for _, original := range []string{"https://v2.example", "https://v3.example"} {
+		updated, err := repo.UpdateURL(ctx, 1, "edit", setOriginal(original))
+		if err != nil || updated.OriginalURL != original {
+			t.Fatalf("unexpected update result %+v, %v", updated, err)
+		}
+	}
+	stored, _ := repo.Get(ctx, "edit")
+	if stored.OriginalURL != "https://v3.example" {
+		t.Fatalf("expected updated original, got %s", stored.OriginalURL)
+	}
+
+	revisions, err = repo.GetRevisions(ctx, 1, "edit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"https://v1.example", "https://v2.example", "https://v3.example"}
+	if len(revisions) != len(want) {
+		t.Fatalf("expected %d revisions, got %d", len(want), len(revisions))
+	}
+	for i, rev := range revisions {
+		if rev.Number != int64(i+1) || rev.OriginalURL != want[i] || rev.AuthorID != 1 || rev.CreatedAt.IsZero() {
+			t.Fatalf("unexpected revision %d: %+v", i+1, rev)
+		}
+	}
+}
+
+func TestFileRepositoryRevisionsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "edit", OriginalURL: "https://v1.example", UserID: 1})
+	_, err = repo.UpdateURL(ctx, 1, "edit", func(u *StoredURL) error {
+		u.OriginalURL = "https://v2.example"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, err := reopened.Get(ctx, "edit")
+	if err != nil || stored.OriginalURL != "https://v2.example" {
+		t.Fatalf("expected updated original after reload, got %+v, %v", stored, err)
+	}
+	revisions, err := reopened.GetRevisions(ctx, 1, "edit")
+	if err != nil || len(revisions) != 2 || revisions[0].CreatedAt.IsZero() {
+		t.Fatalf("expected 2 revisions after reload, got %+v, %v", revisions, err)
+	}
+}
+
+func TestInMemoryRepositoryTrash(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	for _, short := range []string{"first", "second", "foreign"} {
+		userID := int64(1)
+		if short == "foreign" {
+			userID = 2
+		}
+		_ = repo.Add(ctx, StoredURL{ShortID: short, OriginalURL: "https://" + short + ".example", UserID: userID})
+	}
+
+	before := time.Now()
+	repo.MarkDeletedUserURLs(ctx, URLForDelete{ShortID: "first", UserID: 1}, URLForDelete{ShortID: "foreign", UserID: 1})
+	repo.MarkDeletedUserURLs(ctx, URLForDelete{ShortID: "second", UserID: 1})
+	firstDeletedAt := *repo.store["first"].DeletedAt
+	repo.MarkDeletedUserURLs(ctx, URLForDelete{ShortID: "first", UserID: 1})
+	if !repo.store["first"].DeletedAt.Equal(firstDeletedAt) {
+		t.Fatal("expected repeated deletion to keep the original deletion time")
+	}
+
+	trash, err := repo.GetDeletedUserURLs(ctx, 1)
+	if err != nil || len(trash) != 2 || trash[0].ShortID != "second" || trash[1].ShortID != "first" {
+		t.Fatalf("expected second and first in the trash, got %+v, %v", trash, err)
+	}
+	if trash[1].DeletedAt == nil || trash[1].DeletedAt.Before(before) {
+		t.Fatalf("expected deletion time to be recorded, got %v", trash[1].DeletedAt)
+	}
+
+	restored, err := repo.RestoreUserURLs(ctx, 1, time.Now().Add(time.Hour), "first")
+	if err != nil || len(restored) != 0 {
+		t.Fatalf("expected nothing restored past the grace period, got %v, %v", restored, err)
+	}
+	restored, err = repo.RestoreUserURLs(ctx, 1, before, "first", "foreign", "missing")
+	if err != nil || len(restored) != 1 || restored[0] != "first" {
+		t.Fatalf("expected only first restored, got %v, %v", restored, err)
+	}
+	if stored, err := repo.Get(ctx, "first"); err != nil || stored.DeletedAt != nil {
+		t.Fatalf("expected first to be live again, got %+v, %v", stored, err)
+	}
+	if _, err := repo.Get(ctx, "foreign"); err != nil {
+		t.Fatalf("expected foreign to stay live, got %v", err)
+	}
+}
+
+func TestFileRepositoryTrashSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "gone", OriginalURL: "https://gone.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "back", OriginalURL: "https://back.example", UserID: 1})
+	repo.MarkDeletedUserURLs(ctx, URLForDelete{ShortID: "gone", UserID: 1}, URLForDelete{ShortID: "back", UserID: 1})
+	if _, err := repo.RestoreUserURLs(ctx, 1, time.Time{}, "back"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trash, err := reopened.GetDeletedUserURLs(ctx, 1)
+	if err != nil || len(trash) != 1 || trash[0].ShortID != "gone" || trash[0].DeletedAt == nil {
+		t.Fatalf("expected gone in the trash with its deletion time after reload, got %+v, %v", trash, err)
+	}
+	if _, err := reopened.Get(ctx, "back"); err != nil {
+		t.Fatalf("expected back to stay restored after reload, got %v", err)
+	}
+}
+
+func TestInMemoryRepositoryPurgeURLs(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	now := time.Now()
+	longAgo := now.Add(-48 * time.Hour)
+	recently := now.Add(-time.Minute)
+	repo.load(StoredURL{ShortID: "old-deleted", OriginalURL: "https://1.example", UserID: 1, IsDeleted: true, DeletedAt: &longAgo})
+	repo.load(StoredURL{ShortID: "legacy-deleted", OriginalURL: "https://2.example", UserID: 1, IsDeleted: true})
+	repo.load(StoredURL{ShortID: "new-deleted", OriginalURL: "https://3.example", UserID: 1, IsDeleted: true, DeletedAt: &recently})
+	repo.load(StoredURL{ShortID: "old-expired", OriginalURL: "https://4.example", UserID: 1, ExpiresAt: &longAgo, IsExpired: true})
+	repo.load(StoredURL{ShortID: "new-expired", OriginalURL: "https://5.example", UserID: 1, ExpiresAt: &recently, IsExpired: true})
+	repo.load(StoredURL{ShortID: "live", OriginalURL: "https://6.example", UserID: 1})
+	_ = repo.AddClicks(ctx, ClickEvent{ShortID: "old-deleted", ClickedAt: longAgo})
+	repo.loadRevisions(Revision{Number: 1, ShortID: "old-expired"})
+
+	cutoff := now.Add(-time.Hour)
+	counts, err := repo.PurgeURLs(ctx, cutoff, cutoff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (PurgeCounts{Deleted: 2, Expired: 1}) {
+		t.Fatalf("unexpected purge counts %+v", counts)
+	}
+	for _, short := range []string{"old-deleted", "legacy-deleted", "old-expired"} {
+		if _, ok := repo.lookup(short); ok {
+			t.Fatalf("expected %s to be purged", short)
+		}
+	}
+	for _, short := range []string{"new-deleted", "new-expired", "live"} {
+		if _, ok := repo.lookup(short); !ok {
+			t.Fatalf("expected %s to be kept", short)
+		}
+	}
+	if len(repo.allClicks()) != 0 || len(repo.allRevisions()) != 0 {
+		t.Fatal("expected clicks and revisions of purged urls to be removed")
+	}
+	if len(repo.userIndex[1]) != 3 {
+		t.Fatalf("expected purged urls to leave the user index, got %v", repo.userIndex[1])
+	}
+}
+
+func TestFileRepositoryPurgeCompactsFiles(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expiresAt := time.Now().Add(-48 * time.Hour)
+	_ = repo.Add(ctx, StoredURL{ShortID: "expired", OriginalURL: "https://expired.example", UserID: 1, ExpiresAt: &expiresAt})
+	_ = repo.Add(ctx, StoredURL{ShortID: "live", OriginalURL: "https://live.example", UserID: 1})
+	_ = repo.AddClicks(ctx, ClickEvent{ShortID: "expired", ClickedAt: time.Now()}, ClickEvent{ShortID: "live", ClickedAt: time.Now()})
+
+	counts, err := repo.PurgeURLs(ctx, time.Now(), time.Now().Add(-time.Hour))
+	if err != nil || counts.Expired != 1 {
+		t.Fatalf("expected one expired url purged, got %+v, %v", counts, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "expired.example") {
+		t.Fatal("expected the purged url to be removed from the file")
+	}
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := reopened.cache.lookup("expired"); ok {
+		t.Fatal("expected the purged url to stay purged after reload")
+	}
+	if events := reopened.cache.allClicks(); len(events) != 1 || events[0].ShortID != "live" {
+		t.Fatalf("expected only clicks of the live url after reload, got %+v", events)
+	}
+}
+
+func TestFileRepositoryRewriteKeepsConcurrentAppends(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const n = 100
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range n {
+			_ = repo.Add(ctx, StoredURL{ShortID: fmt.Sprintf("s%d", i), OriginalURL: fmt.Sprintf("https://%d.example", i), UserID: 1})
+			_ = repo.AddClicks(ctx, ClickEvent{ShortID: fmt.Sprintf("s%d", i), ClickedAt: time.Now()})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range n {
+			if err := repo.rewrite(); err != nil {
+				t.Errorf("rewrite: %v", err)
+			}
+			if err := repo.rewriteClicks(); err != nil {
+				t.Errorf("rewrite clicks: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range n {
+		short := fmt.Sprintf("s%d", i)
+		if _, err := reopened.Get(ctx, short); err != nil {
+			t.Fatalf("%s lost after reload: %v", short, err)
+		}
+		if stats, err := reopened.GetClickStats(ctx, 1, short); err != nil || stats.Total != 1 {
+			t.Fatalf("clicks of %s after reload: %+v, %v", short, stats, err)
+		}
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestFileRepositoryDeletionQueue(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := URLForDelete{ShortID: "a", UserID: 1}
+	second := URLForDelete{ShortID: "b", UserID: 2}
+	if err := repo.PushDeletions(ctx, first, second, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.RemoveDeletions(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pending, err := reopened.PendingDeletions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pending) != 2 || pending[0] != second || pending[1] != first {
+		t.Fatalf("expected the requests pushed later to stay pending, got %+v", pending)
+	}
+}
+
+func TestInMemoryRepositoryMarkDeletedOutcomes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	_ = repo.Add(ctx, StoredURL{ShortID: "mine", OriginalURL: "https://mine.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "theirs", OriginalURL: "https://theirs.example", UserID: 2})
+
+	outcomes, err := repo.MarkDeletedUserURLs(ctx,
+		URLForDelete{ShortID: "mine", UserID: 1},
+		URLForDelete{ShortID: "theirs", UserID: 1},
+		URLForDelete{ShortID: "missing", UserID: 1},
+		URLForDelete{ShortID: "mine", UserID: 1},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []DeletionOutcome{OutcomeDeleted, OutcomeNotOwned, OutcomeNotFound, OutcomeAlreadyDeleted}
+	if !slices.Equal(outcomes, want) {
+		t.Fatalf("expected outcomes %v, got %v", want, outcomes)
+	}
+	if _, err := repo.Get(ctx, "theirs"); err != nil {
+		t.Fatalf("expected foreign url to stay live, got %v", err)
+	}
+}
+
+func TestFileRepositoryTags(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "a", OriginalURL: "https://a.example", UserID: 1, Tags: []string{"news", "work"}})
+	_ = repo.Add(ctx, StoredURL{ShortID: "b", OriginalURL: "https://b.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "c", OriginalURL: "https://c.example", UserID: 2, Tags: []string{"work"}})
+	if _, err = repo.SetTags(ctx, 2, "b", []string{"work"}); !errors.Is(err, ErrURLNotFound) {
+		t.Fatalf("expected ErrURLNotFound for a foreign URL, got %v", err)
+	}
+	if _, err = repo.SetTags(ctx, 1, "b", []string{"work"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	urls, err := reopened.GetUserURLs(ctx, 1, URLQuery{Tag: "work"})
+	if err != nil || len(urls) != 2 {
+		t.Fatalf("expected 2 URLs tagged work after reload, got %+v, %v", urls, err)
+	}
+	urls, _ = reopened.GetUserURLs(ctx, 1, URLQuery{Tag: "news"})
+	if len(urls) != 1 || urls[0].ShortID != "a" {
+		t.Fatalf("expected only a to be tagged news, got %+v", urls)
+	}
+	tags, err := reopened.GetUserTags(ctx, 1)
+	want := []TagCount{{Tag: "work", Count: 2}, {Tag: "news", Count: 1}}
+	if err != nil || !slices.Equal(tags, want) {
+		t.Fatalf("expected %+v, got %+v, %v", want, tags, err)
+	}
+}
+
+func TestInMemoryRepositoryURLQuery(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, short := range []string{"c", "a", "d", "b"} {
+		repo.load(StoredURL{
+			ShortID:     short,
+			OriginalURL: fmt.Sprintf("https://%s.example.com/path", short),
+			UserID:      1,
+			CreatedAt:   base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	repo.load(StoredURL{ShortID: "e", OriginalURL: "https://other.org", UserID: 1, CreatedAt: base.Add(4 * time.Hour)})
+
+	shorts := func(query URLQuery) string {
+		urls, err := repo.GetUserURLs(ctx, 1, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := make([]string, 0, len(urls))
+		for _, u := range urls {
+			ids = append(ids, u.ShortID)
+		}
+		return strings.Join(ids, ",")
+	}
+	tests := []struct {
+		name  string
+		query URLQuery
+		want  string
+	}{
+		{"created order", URLQuery{}, "c,a,d,b,e"},
+		{"short order desc", URLQuery{Sort: SortByShort, Desc: true}, "e,d,c,b,a"},
+		{"domain", URLQuery{Domain: "example.com"}, "c,a,d,b"},
+		{"subdomain only", URLQuery{Domain: "a.example.com"}, "a"},
+		{"domain suffix is not a subdomain", URLQuery{Domain: "her.org"}, ""},
+		{"created range", URLQuery{CreatedAfter: base.Add(time.Hour), CreatedBefore: base.Add(3 * time.Hour)}, "a,d"},
+		{"limit", URLQuery{Limit: 2}, "c,a"},
+		{"after cursor", URLQuery{Limit: 2, After: &URLCursor{CreatedAt: base.Add(time.Hour), ShortID: "a"}}, "d,b"},
+		{"after cursor desc", URLQuery{Sort: SortByShort, Desc: true, After: &URLCursor{ShortID: "c"}}, "b,a"},
+	}
+	for _, tt := range tests {
+		if got := shorts(tt.query); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestInMemoryRepositorySearch(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	_ = repo.Add(ctx, StoredURL{ShortID: "docs", OriginalURL: "https://Docs.Example.com/guide", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "blog", OriginalURL: "https://blog.example.org", UserID: 1, Tags: []string{"reading"}})
+	_ = repo.Add(ctx, StoredURL{ShortID: "foreign", OriginalURL: "https://docs.example.net", UserID: 2})
+	_ = repo.Add(ctx, StoredURL{ShortID: "talk", OriginalURL: "https://video.test/v/42", UserID: 1,
+		Title: "Conference Keynote", Notes: "Send to the onboarding group"})
+
+	search := func(q string) string {
+		urls, err := repo.GetUserURLs(ctx, 1, URLQuery{Search: q, Sort: SortByShort})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := make([]string, 0, len(urls))
+		for _, u := range urls {
+			ids = append(ids, u.ShortID)
+		}
+		return strings.Join(ids, ",")
+	}
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"docs.example", "docs"},
+		{"example", "blog,docs"},
+		{"read", "blog"},
+		{"keynote", "talk"},
+		{"onboarding", "talk"},
+		{"g", "blog,docs,talk"},
+		{"com/guide", "docs"},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got := search(tt.q); got != tt.want {
+			t.Errorf("search %q: expected %q, got %q", tt.q, tt.want, got)
+		}
+	}
+
+	if _, err := repo.SetTags(ctx, 1, "blog", []string{"later"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := repo.UpdateURL(ctx, 1, "docs", func(u *StoredURL) error {
+		u.OriginalURL = "https://manual.example.com"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := search("read"); got != "" {
+		t.Errorf("expected old tags to be unindexed, got %q", got)
+	}
+	if got := search("guide"); got != "" {
+		t.Errorf("expected old original to be unindexed, got %q", got)
+	}
+	if got := search("manual"); got != "docs" {
+		t.Errorf("expected new original to be indexed, got %q", got)
+	}
+	_, err = repo.UpdateURL(ctx, 1, "talk", func(u *StoredURL) error {
+		u.Title = "Closing talk"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := search("keynote"); got != "" {
+		t.Errorf("expected old title to be unindexed, got %q", got)
+	}
+	if got := search("closing"); got != "talk" {
+		t.Errorf("expected new title to be indexed, got %q", got)
+	}
+}
+
+func TestFileRepositorySearchTitleAndNotes(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db")
+	repo, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = repo.Add(ctx, StoredURL{ShortID: "talk", OriginalURL: "https://video.example.com/v/42", UserID: 1,
+		Title: "Conference Keynote", Notes: "Send to the onboarding group"})
+
+	reopened, err := NewFileRepository(path, NewInMemoryRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, q := range []string{"keynote", "onboarding"} {
+		urls, err := reopened.GetUserURLs(ctx, 1, URLQuery{Search: q})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(urls) != 1 || urls[0].Title != "Conference Keynote" || urls[0].Notes != "Send to the onboarding group" {
+			t.Errorf("search %q after reload: unexpected urls %+v", q, urls)
+		}
+	}
+}
+
+func TestInMemoryRepositoryExportUserURLs(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	_ = repo.Add(ctx, StoredURL{ShortID: "first", OriginalURL: "https://first.example", UserID: 1, Tags: []string{"a"}})
+	_ = repo.Add(ctx, StoredURL{ShortID: "gone", OriginalURL: "https://gone.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "second", OriginalURL: "https://second.example", UserID: 1})
+	_ = repo.Add(ctx, StoredURL{ShortID: "foreign", OriginalURL: "https://foreign.example", UserID: 2})
+	_, _ = repo.MarkDeletedUserURLs(ctx, URLForDelete{ShortID: "gone", UserID: 1})
+	_ = repo.AddClicks(ctx, ClickEvent{ShortID: "second", ClickedAt: time.Now()}, ClickEvent{ShortID: "second", ClickedAt: time.Now()})
+
+	var got []string
+	for export, err := range repo.ExportUserURLs(ctx, 1) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s:%d", export.ShortID, export.Clicks))
+	}
+	if want := []string{"first:0", "second:2"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range repo.ExportUserURLs(canceled, 1) {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+}
+
+func TestInMemoryRepositoryFindOriginals(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		scope DedupScope
+		want  map[string]string
+	}{
+		{DedupGlobal, map[string]string{"https://mine.example": "mine", "https://theirs.example": "theirs"}},
+		{DedupUser, map[string]string{"https://mine.example": "mine"}},
+		{DedupOff, map[string]string{}},
+	} {
+		repo := NewInMemoryRepository(WithDedupScope(tt.scope))
+		_ = repo.Add(ctx, StoredURL{ShortID: "mine", OriginalURL: "https://mine.example", UserID: 1})
+		_ = repo.Add(ctx, StoredURL{ShortID: "theirs", OriginalURL: "https://theirs.example", UserID: 2})
+		found, err := repo.FindOriginals(ctx, 1, "https://mine.example", "https://theirs.example", "https://new.example")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.scope, tt.want, found)
+		}
+	}
+}
@@ -1,17 +1,148 @@
 package storage
 
+import (
+	"iter"
+	"time"
+)
+
 //go:generate easyjson -all models.go
 
 // StoredURL represents a URL record stored in the repository with all its metadata.
+// PasswordHash is a bcrypt hash of the password protecting the link, empty if the link is public.
+// RedirectType is the HTTP status code used to redirect, zero means the server default.
+// QueryPolicy is how the query of a request is merged into the original URL, empty means it is ignored.
+// DeletedAt is when the URL was moved to the trash, nil for live URLs and for URLs deleted before it was recorded.
+// Title and Notes are free-form text of the owner, searched together with the original URL and tags.
+// Tags are normalized by the service: lowercase, unique and sorted.
 type StoredURL struct {
-	ShortID     string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
-	UserID      int64  `json:"user_id"`
-	IsDeleted   bool   `json:"is_deleted"`
+	ShortID      string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	UserID       int64      `json:"user_id"`
+	IsDeleted    bool       `json:"is_deleted"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	IsExpired    bool       `json:"is_expired,omitempty"`
+	ClicksLeft   *int64     `json:"clicks_left,omitempty"`
+	PasswordHash string     `json:"password_hash,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	QueryPolicy  string     `json:"query_policy,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+}
+
+// Expired reports whether the URL has been marked as expired or its expiry time has passed by now.
+func (u StoredURL) Expired(now time.Time) bool {
+	return u.IsExpired || u.ExpiresAt != nil && !u.ExpiresAt.After(now)
+}
+
+// Revision returns a snapshot of the editable fields of the URL.
+func (u StoredURL) Revision(number, authorID int64, createdAt time.Time) Revision {
+	return Revision{
+		Number:       number,
+		ShortID:      u.ShortID,
+		OriginalURL:  u.OriginalURL,
+		RedirectType: u.RedirectType,
+		QueryPolicy:  u.QueryPolicy,
+		ExpiresAt:    u.ExpiresAt,
+		Title:        u.Title,
+		Notes:        u.Notes,
+		AuthorID:     authorID,
+		CreatedAt:    createdAt,
+	}
+}
+
+// Revision represents the state of the editable fields of a URL after a change.
+// Revision 1 is the state the URL was created with; a zero CreatedAt means the creation time is unknown.
+type Revision struct {
+	Number       int64      `json:"revision"`
+	ShortID      string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	QueryPolicy  string     `json:"query_policy,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	AuthorID     int64      `json:"author_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// URLExport is a live URL of a user with the number of times it was followed.
+type URLExport struct {
+	StoredURL
+	Clicks int64 `json:"clicks"`
+}
+
+// URLExportSeq is a sequence of exported URLs that ends after the first error.
+type URLExportSeq iter.Seq2[URLExport, error]
+
+// TagCount holds how many live URLs of a user carry the tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// DeletionOutcome is the result of a single deletion request.
+type DeletionOutcome string
+
+// Deletion outcomes.
+const (
+	OutcomeDeleted        DeletionOutcome = "deleted"
+	OutcomeAlreadyDeleted DeletionOutcome = "already_deleted"
+	OutcomeNotFound       DeletionOutcome = "not_found"
+	OutcomeNotOwned       DeletionOutcome = "not_owned"
+)
+
+// PurgeCounts holds how many URLs were physically removed by a purge.
+// URLs that were both deleted and expired are counted as deleted.
+type PurgeCounts struct {
+	Deleted int64
+	Expired int64
 }
 
 // URLForDelete represents a URL deletion request containing the short ID and user ID.
 type URLForDelete struct {
-	ShortID string
-	UserID  int64
+	ShortID string `json:"short_url"`
+	UserID  int64  `json:"user_id"`
+}
+
+// ClickEvent represents a single follow of a short link.
+// Referrer holds the host of the referring page and Browser the browser family parsed from UserAgent.
+type ClickEvent struct {
+	ShortID   string    `json:"short_url"`
+	ClickedAt time.Time `json:"clicked_at"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Browser   string    `json:"browser,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+}
+
+// ClickStats holds aggregated click counts of a short link.
+// ByDay is keyed by UTC date in the 2006-01-02 layout.
+type ClickStats struct {
+	Total      int64            `json:"total"`
+	ByDay      map[string]int64 `json:"by_day"`
+	ByReferrer map[string]int64 `json:"by_referrer"`
+	ByBrowser  map[string]int64 `json:"by_browser"`
+}
+
+// ClickDayLayout is the layout of the ClickStats.ByDay keys.
+const ClickDayLayout = "2006-01-02"
+
+// NewClickStats creates empty ClickStats with initialized breakdowns.
+func NewClickStats() ClickStats {
+	return ClickStats{
+		ByDay:      make(map[string]int64),
+		ByReferrer: make(map[string]int64),
+		ByBrowser:  make(map[string]int64),
+	}
+}
+
+// Count adds the click event to the stats.
+func (s *ClickStats) Count(event ClickEvent) {
+	s.Total++
+	s.ByDay[event.ClickedAt.UTC().Format(ClickDayLayout)]++
+	s.ByReferrer[event.Referrer]++
+	s.ByBrowser[event.Browser]++
 }
@@ -4,6 +4,7 @@ package storage
 
 import (
 	json "encoding/json"
+	time "time"
 
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
@@ -37,9 +38,9 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage(in *jlexer.Lex
 			continue
 		}
 		switch key {
-		case "ShortID":
+		case "short_url":
 			out.ShortID = string(in.String())
-		case "UserID":
+		case "user_id":
 			out.UserID = int64(in.Int64())
 		default:
 			in.SkipRecursive()
@@ -56,12 +57,12 @@ func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage(out *jwriter.W
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"ShortID\":"
+		const prefix string = ",\"short_url\":"
 		out.RawString(prefix[1:])
 		out.String(string(in.ShortID))
 	}
 	{
-		const prefix string = ",\"UserID\":"
+		const prefix string = ",\"user_id\":"
 		out.RawString(prefix)
 		out.Int64(int64(in.UserID))
 	}
@@ -91,7 +92,7 @@ func (v *URLForDelete) UnmarshalJSON(data []byte) error {
 func (v *URLForDelete) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(in *jlexer.Lexer, out *StoredURL) {
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(in *jlexer.Lexer, out *URLExport) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -110,6 +111,8 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(in *jlexer.Le
 			continue
 		}
 		switch key {
+		case "clicks":
+			out.Clicks = int64(in.Int64())
 		case "short_url":
 			out.ShortID = string(in.String())
 		case "original_url":
@@ -118,6 +121,79 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(in *jlexer.Le
 			out.UserID = int64(in.Int64())
 		case "is_deleted":
 			out.IsDeleted = bool(in.Bool())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "is_expired":
+			out.IsExpired = bool(in.Bool())
+		case "clicks_left":
+			if in.IsNull() {
+				in.Skip()
+				out.ClicksLeft = nil
+			} else {
+				if out.ClicksLeft == nil {
+					out.ClicksLeft = new(int64)
+				}
+				*out.ClicksLeft = int64(in.Int64())
+			}
+		case "password_hash":
+			out.PasswordHash = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Tags = append(out.Tags, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -128,7 +204,309 @@ func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(in *jlexer.Le
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(out *jwriter.Writer, in StoredURL) {
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(out *jwriter.Writer, in URLExport) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"clicks\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Clicks))
+	}
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortID))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"is_deleted\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsDeleted))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.IsExpired {
+		const prefix string = ",\"is_expired\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsExpired))
+	}
+	if in.ClicksLeft != nil {
+		const prefix string = ",\"clicks_left\":"
+		out.RawString(prefix)
+		out.Int64(int64(*in.ClicksLeft))
+	}
+	if in.PasswordHash != "" {
+		const prefix string = ",\"password_hash\":"
+		out.RawString(prefix)
+		out.String(string(in.PasswordHash))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	if true {
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Tags {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v URLExport) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v URLExport) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *URLExport) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *URLExport) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage2(in *jlexer.Lexer, out *TagCount) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "tag":
+			out.Tag = string(in.String())
+		case "count":
+			out.Count = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage2(out *jwriter.Writer, in TagCount) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"tag\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Tag))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v TagCount) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v TagCount) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *TagCount) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *TagCount) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage2(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage3(in *jlexer.Lexer, out *StoredURL) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "short_url":
+			out.ShortID = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "is_deleted":
+			out.IsDeleted = bool(in.Bool())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "is_expired":
+			out.IsExpired = bool(in.Bool())
+		case "clicks_left":
+			if in.IsNull() {
+				in.Skip()
+				out.ClicksLeft = nil
+			} else {
+				if out.ClicksLeft == nil {
+					out.ClicksLeft = new(int64)
+				}
+				*out.ClicksLeft = int64(in.Int64())
+			}
+		case "password_hash":
+			out.PasswordHash = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Tags = append(out.Tags, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage3(out *jwriter.Writer, in StoredURL) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -152,29 +530,587 @@ func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(out *jwriter.
 		out.RawString(prefix)
 		out.Bool(bool(in.IsDeleted))
 	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.IsExpired {
+		const prefix string = ",\"is_expired\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsExpired))
+	}
+	if in.ClicksLeft != nil {
+		const prefix string = ",\"clicks_left\":"
+		out.RawString(prefix)
+		out.Int64(int64(*in.ClicksLeft))
+	}
+	if in.PasswordHash != "" {
+		const prefix string = ",\"password_hash\":"
+		out.RawString(prefix)
+		out.String(string(in.PasswordHash))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	if true {
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Tags {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v StoredURL) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(&w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v StoredURL) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage1(w, v)
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *StoredURL) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(&r, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *StoredURL) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage1(l, v)
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage3(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage4(in *jlexer.Lexer, out *Revision) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "revision":
+			out.Number = int64(in.Int64())
+		case "short_url":
+			out.ShortID = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "redirect_type":
+			out.RedirectType = int(in.Int())
+		case "query_policy":
+			out.QueryPolicy = string(in.String())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "title":
+			out.Title = string(in.String())
+		case "notes":
+			out.Notes = string(in.String())
+		case "author_id":
+			out.AuthorID = int64(in.Int64())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage4(out *jwriter.Writer, in Revision) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Number))
+	}
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortID))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.RedirectType != 0 {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectType))
+	}
+	if in.QueryPolicy != "" {
+		const prefix string = ",\"query_policy\":"
+		out.RawString(prefix)
+		out.String(string(in.QueryPolicy))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.Notes != "" {
+		const prefix string = ",\"notes\":"
+		out.RawString(prefix)
+		out.String(string(in.Notes))
+	}
+	{
+		const prefix string = ",\"author_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.AuthorID))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Revision) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Revision) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Revision) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Revision) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage4(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage5(in *jlexer.Lexer, out *PurgeCounts) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "Deleted":
+			out.Deleted = int64(in.Int64())
+		case "Expired":
+			out.Expired = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage5(out *jwriter.Writer, in PurgeCounts) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"Deleted\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Deleted))
+	}
+	{
+		const prefix string = ",\"Expired\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Expired))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PurgeCounts) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PurgeCounts) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PurgeCounts) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PurgeCounts) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage5(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage6(in *jlexer.Lexer, out *ClickStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "total":
+			out.Total = int64(in.Int64())
+		case "by_day":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByDay = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v7 int64
+					v7 = int64(in.Int64())
+					(out.ByDay)[key] = v7
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "by_referrer":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByReferrer = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v8 int64
+					v8 = int64(in.Int64())
+					(out.ByReferrer)[key] = v8
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "by_browser":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.ByBrowser = make(map[string]int64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v9 int64
+					v9 = int64(in.Int64())
+					(out.ByBrowser)[key] = v9
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage6(out *jwriter.Writer, in ClickStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Total))
+	}
+	{
+		const prefix string = ",\"by_day\":"
+		out.RawString(prefix)
+		if in.ByDay == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v10First := true
+			for v10Name, v10Value := range in.ByDay {
+				if v10First {
+					v10First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v10Name))
+				out.RawByte(':')
+				out.Int64(int64(v10Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"by_referrer\":"
+		out.RawString(prefix)
+		if in.ByReferrer == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v11First := true
+			for v11Name, v11Value := range in.ByReferrer {
+				if v11First {
+					v11First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v11Name))
+				out.RawByte(':')
+				out.Int64(int64(v11Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"by_browser\":"
+		out.RawString(prefix)
+		if in.ByBrowser == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v12First := true
+			for v12Name, v12Value := range in.ByBrowser {
+				if v12First {
+					v12First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v12Name))
+				out.RawByte(':')
+				out.Int64(int64(v12Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ClickStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ClickStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ClickStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ClickStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage6(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage7(in *jlexer.Lexer, out *ClickEvent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "short_url":
+			out.ShortID = string(in.String())
+		case "clicked_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ClickedAt).UnmarshalJSON(data))
+			}
+		case "referrer":
+			out.Referrer = string(in.String())
+		case "user_agent":
+			out.UserAgent = string(in.String())
+		case "browser":
+			out.Browser = string(in.String())
+		case "ip":
+			out.IP = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage7(out *jwriter.Writer, in ClickEvent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ShortID))
+	}
+	{
+		const prefix string = ",\"clicked_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ClickedAt).MarshalJSON())
+	}
+	if in.Referrer != "" {
+		const prefix string = ",\"referrer\":"
+		out.RawString(prefix)
+		out.String(string(in.Referrer))
+	}
+	if in.UserAgent != "" {
+		const prefix string = ",\"user_agent\":"
+		out.RawString(prefix)
+		out.String(string(in.UserAgent))
+	}
+	if in.Browser != "" {
+		const prefix string = ",\"browser\":"
+		out.RawString(prefix)
+		out.String(string(in.Browser))
+	}
+	if in.IP != "" {
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ClickEvent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ClickEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComCmrdAShortenerInternalStorage7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ClickEvent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ClickEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComCmrdAShortenerInternalStorage7(l, v)
 }
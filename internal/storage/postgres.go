@@ -4,24 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// purgeChunkSize is how many URLs are removed by a single purge statement.
+const purgeChunkSize = 1000
+
 // PgRepository implements the Repository interface using PostgreSQL as the storage backend.
 type PgRepository struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	dedup DedupScope
 }
 
 // NewPgRepository creates a new PgRepository instance with a PostgreSQL connection pool.
 // It initializes the database schema by calling Bootstrap().
-func NewPgRepository(ctx context.Context, dsn string) (*PgRepository, error) {
+// Original URLs are deduplicated globally unless WithDedupScope says otherwise.
+func NewPgRepository(ctx context.Context, dsn string, opts ...Option) (*PgRepository, error) {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
-	r := &PgRepository{pool: pool}
+	r := &PgRepository{pool: pool, dedup: newOptions(opts).dedup}
 	err = r.Bootstrap()
 	if err != nil {
 		return nil, err
@@ -53,19 +62,185 @@ func (r PgRepository) Bootstrap() error {
 		return err
 	}
 	_, err = r.pool.Exec(context.Background(), `
-		CREATE UNIQUE INDEX IF NOT EXISTS urls_original_uindex
-		ON url (original)
+		CREATE INDEX IF NOT EXISTS user_id_index
+		ON url (user_id)
 	`)
 	if err != nil {
 		return err
 	}
 	_, err = r.pool.Exec(context.Background(), `
-		CREATE INDEX IF NOT EXISTS user_id_index
-		ON url (user_id)
+		ALTER TABLE url
+			ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE,
+			ADD COLUMN IF NOT EXISTS is_expired bool NOT NULL DEFAULT FALSE,
+			ADD COLUMN IF NOT EXISTS clicks_left BIGINT,
+			ADD COLUMN IF NOT EXISTS password_hash text NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS redirect_type SMALLINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS query_policy text NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS title text NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE
+	`)
+	if err != nil {
+		return err
+	}
+	err = r.bootstrapDedupIndex()
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE INDEX IF NOT EXISTS urls_expires_at_index
+		ON url (expires_at)
+		WHERE expires_at IS NOT NULL AND NOT is_expired
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE INDEX IF NOT EXISTS urls_deleted_at_index
+		ON url (deleted_at)
+		WHERE is_deleted
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE INDEX IF NOT EXISTS urls_user_created_at_index
+		ON url (user_id, created_at, short)
+		WHERE NOT is_deleted
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE TABLE IF NOT EXISTS click
+		(
+			id         BIGSERIAL PRIMARY KEY,
+			short      text NOT NULL,
+			clicked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			referrer   text NOT NULL DEFAULT '',
+			user_agent text NOT NULL DEFAULT '',
+			browser    text NOT NULL DEFAULT '',
+			ip         text NOT NULL DEFAULT ''
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE INDEX IF NOT EXISTS click_short_index
+		ON click (short, clicked_at)
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE TABLE IF NOT EXISTS url_tag
+		(
+			short text NOT NULL,
+			tag   text NOT NULL,
+			PRIMARY KEY (short, tag)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE INDEX IF NOT EXISTS url_tag_tag_index
+		ON url_tag (tag, short)
+	`)
+	if err != nil {
+		return err
+	}
+	err = r.bootstrapSearchIndexes()
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE TABLE IF NOT EXISTS deletion_queue
+		(
+			id        BIGSERIAL PRIMARY KEY,
+			short     text NOT NULL,
+			user_id   BIGINT NOT NULL,
+			queued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)
 	`)
 	if err != nil {
 		return err
 	}
+	_, err = r.pool.Exec(context.Background(), `
+		CREATE TABLE IF NOT EXISTS url_revision
+		(
+			id            BIGSERIAL PRIMARY KEY,
+			short         text NOT NULL,
+			revision      BIGINT NOT NULL,
+			original      text NOT NULL,
+			redirect_type SMALLINT NOT NULL DEFAULT 0,
+			query_policy  text NOT NULL DEFAULT '',
+			expires_at    TIMESTAMP WITH TIME ZONE,
+			title         text NOT NULL DEFAULT '',
+			notes         text NOT NULL DEFAULT '',
+			author_id     BIGINT NOT NULL,
+			created_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (short, revision)
+		)
+	`)
+	return err
+}
+
+// bootstrapDedupIndex makes the unique index on original URLs match the dedup scope.
+// The index leaves out expired URLs, so that an original can be shortened again once its link expired.
+// Indexes of other scopes and the earlier full indexes are dropped, so switching the scope only needs a restart.
+// Switching to a narrower scope fails if the table already holds duplicates for it.
+func (r PgRepository) bootstrapDedupIndex() error {
+	statements := []string{
+		`DROP INDEX IF EXISTS urls_original_uindex`,
+		`DROP INDEX IF EXISTS urls_user_original_uindex`,
+	}
+	switch r.dedup {
+	case DedupGlobal:
+		statements = append(statements,
+			`DROP INDEX IF EXISTS urls_user_original_live_uindex`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS urls_original_live_uindex ON url (original) WHERE NOT is_expired`,
+		)
+	case DedupUser:
+		statements = append(statements,
+			`DROP INDEX IF EXISTS urls_original_live_uindex`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS urls_user_original_live_uindex ON url (user_id, original) WHERE NOT is_expired`,
+		)
+	case DedupOff:
+		statements = append(statements,
+			`DROP INDEX IF EXISTS urls_original_live_uindex`,
+			`DROP INDEX IF EXISTS urls_user_original_live_uindex`,
+		)
+	default:
+		return fmt.Errorf("unknown dedup scope %q", r.dedup)
+	}
+	for _, statement := range statements {
+		_, err := r.pool.Exec(context.Background(), statement)
+		if err != nil {
+			return fmt.Errorf("failed to apply %s dedup scope: %w", r.dedup, err)
+		}
+	}
+	return nil
+}
+
+// bootstrapSearchIndexes creates the trigram indexes serving substring search over the original URLs,
+// titles, notes and tags.
+// The pg_trgm extension is trusted, so the owner of the database can create it.
+func (r PgRepository) bootstrapSearchIndexes() error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS urls_original_trgm_index ON url USING gin (lower(original) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS urls_title_trgm_index ON url USING gin (lower(title) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS urls_notes_trgm_index ON url USING gin (lower(notes) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS url_tag_tag_trgm_index ON url_tag USING gin (tag gin_trgm_ops)`,
+	}
+	for _, statement := range statements {
+		_, err := r.pool.Exec(context.Background(), statement)
+		if err != nil {
+			return fmt.Errorf("failed to create search indexes: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -74,69 +249,425 @@ func (r PgRepository) Ping(ctx context.Context) error {
 	return r.pool.Ping(ctx)
 }
 
-// Get retrieves the original URL for a given short URL identifier from PostgreSQL.
-func (r PgRepository) Get(ctx context.Context, short string) (string, error) {
-	var original string
-	var isDeleted bool
-	err := r.pool.QueryRow(ctx, "SELECT original, is_deleted FROM url  WHERE short=$1", short).Scan(&original, &isDeleted)
+// Get retrieves the URL record for a given short URL identifier from PostgreSQL.
+func (r PgRepository) Get(ctx context.Context, short string) (StoredURL, error) {
+	url := StoredURL{ShortID: short}
+	var isExpired bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT original, user_id, is_deleted, expires_at,
+			   is_expired OR COALESCE(expires_at <= NOW(), FALSE), clicks_left, password_hash, redirect_type,
+			   query_policy, title, notes, created_at
+		FROM url
+		WHERE short=$1
+	`, short).Scan(&url.OriginalURL, &url.UserID, &url.IsDeleted, &url.ExpiresAt, &isExpired, &url.ClicksLeft, &url.PasswordHash, &url.RedirectType,
+		&url.QueryPolicy, &url.Title, &url.Notes, &url.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return StoredURL{}, ErrURLNotFound
+	}
 	if err != nil {
-		return "", err
+		return StoredURL{}, err
+	}
+	if url.IsDeleted {
+		return StoredURL{}, ErrURLIsDeleted
 	}
-	if isDeleted {
-		return "", ErrURLIsDeleted
+	if isExpired {
+		return StoredURL{}, ErrURLIsExpired
 	}
-	return original, nil
+	if url.ClicksLeft != nil && *url.ClicksLeft <= 0 {
+		return StoredURL{}, ErrURLClicksExhausted
+	}
+	return url, nil
+}
+
+// ConsumeClick atomically takes one click from a click-limited URL and returns how many are left.
+func (r PgRepository) ConsumeClick(ctx context.Context, short string) (int64, error) {
+	var left int64
+	err := r.pool.QueryRow(ctx, `
+		UPDATE url
+		SET clicks_left = clicks_left - 1
+		WHERE short = $1 AND clicks_left > 0
+		RETURNING clicks_left
+	`, short).Scan(&left)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrURLClicksExhausted
+	}
+	if err != nil {
+		return 0, err
+	}
+	return left, nil
 }
 
 // Add stores a new URL mapping in PostgreSQL, checking for duplicates.
-func (r PgRepository) Add(ctx context.Context, short, original string, userID int64) error {
+// It returns ErrOriginalExist if the original is already stored within the dedup scope
+// and ErrShortExist if the short ID is occupied. Expired URLs do not count as stored.
+func (r PgRepository) Add(ctx context.Context, url StoredURL) error {
+	if err := markExpiredOriginals(ctx, r.pool, url.OriginalURL); err != nil {
+		return err
+	}
 	row := r.pool.QueryRow(ctx, `
 		WITH ins AS (
 			INSERT INTO url
-				(user_id, short, original)
-				VALUES ($1, $2, $3)
-				ON CONFLICT DO NOTHING),
+				(user_id, short, original, expires_at, clicks_left, password_hash, redirect_type, query_policy, title, notes)
+				VALUES ($1, $2, $3, $4, $5, $6, $8, $10, $11, $12)
+				ON CONFLICT DO NOTHING
+				RETURNING short),
+			 tags AS (INSERT INTO url_tag (short, tag)
+				SELECT short, unnest($9::text[]) FROM ins),
 			 dup AS (SELECT short
 					 FROM url
-					 WHERE original = $3)
-		SELECT short
-		FROM dup
-	`, userID, short, original)
-	var existingShort string
-	err := row.Scan(&existingShort)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
+					 WHERE original = $3
+					   AND NOT is_expired
+					   AND CASE $7::text
+							   WHEN 'global' THEN TRUE
+							   WHEN 'user' THEN user_id = $1
+							   ELSE FALSE
+						   END
+					 LIMIT 1)
+		SELECT (SELECT short FROM ins), (SELECT short FROM dup)
+	`, url.UserID, url.ShortID, url.OriginalURL, url.ExpiresAt, url.ClicksLeft, url.PasswordHash, string(r.dedup), url.RedirectType, url.Tags,
+		url.QueryPolicy, url.Title, url.Notes)
+	var inserted, existingShort *string
+	err := row.Scan(&inserted, &existingShort)
+	if err != nil {
 		return err
 	}
-	return NewOriginalExistError(existingShort)
+	if inserted != nil {
+		return nil
+	}
+	if existingShort != nil {
+		return NewOriginalExistError(*existingShort)
+	}
+	return NewShortExistError(url.ShortID)
 }
 
-// AddBatch stores multiple URL mappings in PostgreSQL using a batch operation for efficiency.
+// AddBatch stores multiple URL mappings in PostgreSQL inside a transaction.
+// The URLs are copied into a temporary table with COPY and moved from there with a single statement.
+// Nothing is stored if any of the short IDs is already occupied or if any of the originals
+// is already stored within the dedup scope of the user or repeated in the batch.
 func (r PgRepository) AddBatch(ctx context.Context, userID int64, batch ...StoredURL) error {
-	b := &pgx.Batch{}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	originals := make([]string, len(batch))
+	for i, url := range batch {
+		originals[i] = url.OriginalURL
+	}
+	if err := markExpiredOriginals(ctx, tx, originals...); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		CREATE TEMPORARY TABLE url_batch
+		(
+			pos           INT NOT NULL,
+			short         text NOT NULL,
+			original      text NOT NULL,
+			expires_at    TIMESTAMP WITH TIME ZONE,
+			clicks_left   BIGINT,
+			password_hash text NOT NULL,
+			redirect_type SMALLINT NOT NULL,
+			query_policy  text NOT NULL,
+			title         text NOT NULL,
+			notes         text NOT NULL,
+			tags          text[]
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"url_batch"},
+		[]string{"pos", "short", "original", "expires_at", "clicks_left", "password_hash", "redirect_type", "query_policy", "title", "notes",
+			"tags"},
+		pgx.CopyFromSlice(len(batch), func(i int) ([]any, error) {
+			url := batch[i]
+			return []any{i, url.ShortID, url.OriginalURL, url.ExpiresAt, url.ClicksLeft, url.PasswordHash, url.RedirectType, url.QueryPolicy,
+				url.Title, url.Notes, url.Tags}, nil
+		}))
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH ins AS (
+			INSERT INTO url (short, original, user_id, expires_at, clicks_left, password_hash, redirect_type, query_policy, title, notes)
+			SELECT short, original, $1, expires_at, clicks_left, password_hash, redirect_type, query_policy, title, notes
+			FROM url_batch
+			ORDER BY pos
+			ON CONFLICT DO NOTHING
+			RETURNING short
+		), tags AS (
+			INSERT INTO url_tag (short, tag)
+			SELECT b.short, unnest(b.tags) FROM url_batch b JOIN ins USING (short)
+		)
+		SELECT short FROM ins
+	`, userID)
+	if err != nil {
+		return err
+	}
+	inserted, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
 	for _, url := range batch {
-		b.Queue("INSERT INTO url (short, original, user_id) VALUES ($1, $2, $3)", url.ShortID, url.OriginalURL, userID)
+		if slices.Contains(inserted, url.ShortID) {
+			continue
+		}
+		// The rows inserted above are visible here, so an original repeated in the batch is found too.
+		var existingShort string
+		err := tx.QueryRow(ctx, `
+			SELECT short
+			FROM url
+			WHERE original = $1
+			  AND NOT is_expired
+			  AND CASE $2::text
+					  WHEN 'global' THEN TRUE
+					  WHEN 'user' THEN user_id = $3
+					  ELSE FALSE
+				  END
+			LIMIT 1
+		`, url.OriginalURL, string(r.dedup), userID).Scan(&existingShort)
+		if err == nil {
+			return NewOriginalExistError(existingShort)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return NewShortExistError(url.ShortID)
 	}
-	results := r.pool.SendBatch(ctx, b)
-	defer results.Close()
+	return tx.Commit(ctx)
+}
+
+// execer is implemented by both the pool and transactions.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// markExpiredOriginals flags the URLs of the originals whose expiry has passed, ahead of the expiry sweep,
+// so that they leave the unique index on originals before the originals are stored again.
+func markExpiredOriginals(ctx context.Context, db execer, originals ...string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE url
+		SET is_expired = TRUE
+		WHERE original = ANY($1) AND expires_at <= NOW() AND NOT is_expired
+	`, originals)
+	return err
+}
+
+// FindOriginals looks for the originals within the dedup scope of the user in PostgreSQL.
+func (r PgRepository) FindOriginals(ctx context.Context, userID int64, originals ...string) (map[string]string, error) {
+	found := make(map[string]string)
+	if r.dedup == DedupOff || len(originals) == 0 {
+		return found, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (original) original, short
+		FROM url
+		WHERE original = ANY($1) AND ($2::text = 'global' OR user_id = $3)
+		  AND NOT is_expired AND COALESCE(expires_at > NOW(), TRUE)
+		ORDER BY original, id
+	`, originals, string(r.dedup), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var original, short string
+		if err := rows.Scan(&original, &short); err != nil {
+			return nil, err
+		}
+		found[original] = short
+	}
+	return found, rows.Err()
+}
 
-	for i := range len(batch) {
-		_, err := results.Exec()
+// GetUserURLs retrieves the non-deleted URLs created by a specific user that match the query from PostgreSQL.
+func (r PgRepository) GetUserURLs(ctx context.Context, userID int64, query URLQuery) ([]StoredURL, error) {
+	var after *time.Time
+	var afterShort string
+	if query.After != nil {
+		after, afterShort = &query.After.CreatedAt, query.After.ShortID
+	}
+	var limit *int
+	if query.Limit > 0 {
+		limit = &query.Limit
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT short, original, expires_at, is_expired, clicks_left, redirect_type, query_policy, title, notes, created_at,
+			ARRAY(SELECT tag FROM url_tag t WHERE t.short = url.short ORDER BY tag)
+		FROM url
+		WHERE user_id = $1 AND NOT is_deleted
+			AND ($2 = '' OR EXISTS(SELECT 1 FROM url_tag t WHERE t.short = url.short AND t.tag = $2))
+			AND ($3 = '' OR `+pgHost+` = $3 OR right(`+pgHost+`, length($3) + 1) = '.' || $3)
+			AND ($4::timestamptz IS NULL OR created_at >= $4)
+			AND ($5::timestamptz IS NULL OR created_at < $5)
+			AND `+pgAfter(query)+`
+			AND ($9 = '' OR lower(original) LIKE $9 OR lower(title) LIKE $9 OR lower(notes) LIKE $9
+				OR EXISTS(SELECT 1 FROM url_tag t WHERE t.short = url.short AND t.tag LIKE $9))
+		ORDER BY `+pgOrder(query)+`
+		LIMIT $8
+	`, userID, query.Tag, query.Domain, pgTime(query.CreatedAfter), pgTime(query.CreatedBefore), after, afterShort, limit,
+		pgContains(query.Search))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls = make([]StoredURL, 0)
+	for rows.Next() {
+		url := StoredURL{UserID: userID}
+		if err := rows.Scan(&url.ShortID, &url.OriginalURL, &url.ExpiresAt, &url.IsExpired, &url.ClicksLeft, &url.RedirectType, &url.QueryPolicy,
+			&url.Title, &url.Notes, &url.CreatedAt, &url.Tags); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+// pgHost extracts the lowercase host from the original URL column.
+const pgHost = `lower(substring(original from '^[^:/?#]+://(?:[^/?#@]*@)?([^/?#:]+)'))`
+
+// pgAfter returns the keyset condition of a URL query on its cursor parameters,
+// $6 (created_at, NULL without a cursor) and $7 (short).
+func pgAfter(q URLQuery) string {
+	op := " > "
+	if q.Desc {
+		op = " < "
+	}
+	if q.Sort == SortByShort {
+		return "($6::timestamptz IS NULL OR short" + op + "$7)"
+	}
+	return "($6::timestamptz IS NULL OR (created_at, short)" + op + "($6, $7))"
+}
+
+// pgOrder returns the ORDER BY clause of a URL query.
+func pgOrder(q URLQuery) string {
+	dir := " ASC"
+	if q.Desc {
+		dir = " DESC"
+	}
+	if q.Sort == SortByShort {
+		return "short" + dir
+	}
+	return "created_at" + dir + ", short" + dir
+}
+
+// pgContains returns a LIKE pattern matching the strings that contain the substring, empty for an empty one.
+func pgContains(substring string) string {
+	if substring == "" {
+		return ""
+	}
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(substring) + "%"
+}
+
+// pgTime converts a zero time to NULL.
+func pgTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ExportUserURLs streams the live URLs of a user from PostgreSQL row by row.
+func (r PgRepository) ExportUserURLs(ctx context.Context, userID int64) URLExportSeq {
+	return func(yield func(URLExport, error) bool) {
+		rows, err := r.pool.Query(ctx, `
+			SELECT short, original, expires_at, is_expired, clicks_left, redirect_type, query_policy, title, notes, created_at,
+				ARRAY(SELECT tag FROM url_tag t WHERE t.short = url.short ORDER BY tag),
+				(SELECT count(*) FROM click c WHERE c.short = url.short)
+			FROM url
+			WHERE user_id = $1 AND NOT is_deleted
+			ORDER BY created_at, short
+		`, userID)
 		if err != nil {
-			return fmt.Errorf("error executing batch command %d: %w", i, err)
+			yield(URLExport{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			export := URLExport{StoredURL: StoredURL{UserID: userID}}
+			url := &export.StoredURL
+			err := rows.Scan(&url.ShortID, &url.OriginalURL, &url.ExpiresAt, &url.IsExpired, &url.ClicksLeft,
+				&url.RedirectType, &url.QueryPolicy, &url.Title, &url.Notes, &url.CreatedAt, &url.Tags, &export.Clicks)
+			if err != nil {
+				yield(URLExport{}, err)
+				return
+			}
+			if !yield(export, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(URLExport{}, err)
 		}
 	}
-	return nil
 }
 
-// GetUserURLs retrieves all non-deleted URLs created by a specific user from PostgreSQL.
-func (r PgRepository) GetUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
+// SetTags replaces the tags of a URL owned by the user in PostgreSQL.
+func (r PgRepository) SetTags(ctx context.Context, userID int64, short string, tags []string) (StoredURL, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	url := StoredURL{ShortID: short, UserID: userID}
+	err = tx.QueryRow(ctx, `
+		SELECT original, expires_at, is_expired, clicks_left, redirect_type, query_policy, title, notes, created_at
+		FROM url
+		WHERE short = $1 AND user_id = $2 AND NOT is_deleted
+		FOR UPDATE
+	`, short, userID).Scan(&url.OriginalURL, &url.ExpiresAt, &url.IsExpired, &url.ClicksLeft, &url.RedirectType, &url.QueryPolicy,
+		&url.Title, &url.Notes, &url.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return StoredURL{}, ErrURLNotFound
+	}
+	if err != nil {
+		return StoredURL{}, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM url_tag WHERE short = $1`, short); err != nil {
+		return StoredURL{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO url_tag (short, tag)
+		SELECT $1, unnest($2::text[])
+	`, short, tags); err != nil {
+		return StoredURL{}, err
+	}
+	url.Tags = tags
+	return url, tx.Commit(ctx)
+}
+
+// GetUserTags counts the tags of the non-deleted URLs of a user in PostgreSQL, most used first.
+func (r PgRepository) GetUserTags(ctx context.Context, userID int64) ([]TagCount, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT short, original, is_deleted
+		SELECT t.tag, COUNT(*)
+		FROM url_tag t
+		JOIN url u ON u.short = t.short
+		WHERE u.user_id = $1 AND NOT u.is_deleted
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (TagCount, error) {
+		var tag TagCount
+		err := row.Scan(&tag.Tag, &tag.Count)
+		return tag, err
+	})
+}
+
+// GetDeletedUserURLs retrieves the deleted URLs of a user from PostgreSQL, most recently deleted first.
+func (r PgRepository) GetDeletedUserURLs(ctx context.Context, userID int64) ([]StoredURL, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT short, original, expires_at, is_expired, clicks_left, redirect_type, deleted_at
 		FROM url
-		WHERE user_id = $1
+		WHERE user_id = $1 AND is_deleted
+		ORDER BY deleted_at DESC NULLS LAST
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -145,31 +676,362 @@ func (r PgRepository) GetUserURLs(ctx context.Context, userID int64) ([]StoredUR
 
 	var urls = make([]StoredURL, 0)
 	for rows.Next() {
-		url := StoredURL{}
-		if err := rows.Scan(&url.ShortID, &url.OriginalURL, &url.IsDeleted); err != nil {
+		url := StoredURL{UserID: userID, IsDeleted: true}
+		if err := rows.Scan(&url.ShortID, &url.OriginalURL, &url.ExpiresAt, &url.IsExpired, &url.ClicksLeft, &url.RedirectType, &url.DeletedAt); err != nil {
 			return nil, err
 		}
-		if !url.IsDeleted {
-			urls = append(urls, url)
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+// RestoreUserURLs takes the URLs of the user deleted at or after since out of the trash in PostgreSQL.
+func (r PgRepository) RestoreUserURLs(ctx context.Context, userID int64, since time.Time, shorts ...string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE url SET is_deleted = FALSE, deleted_at = NULL
+		WHERE user_id = $1 AND short = ANY($2) AND is_deleted AND deleted_at >= $3
+		RETURNING short
+	`, userID, shorts, since)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// PurgeURLs removes the URLs deleted before deletedBefore and those expired before expiredBefore
+// from PostgreSQL together with their clicks and revisions.
+// URLs are removed in chunks of purgeChunkSize, each in its own statement, so that locks are held briefly.
+func (r PgRepository) PurgeURLs(ctx context.Context, deletedBefore, expiredBefore time.Time) (PurgeCounts, error) {
+	var counts PurgeCounts
+	var err error
+	counts.Deleted, err = r.purgeChunks(ctx, "is_deleted AND (deleted_at IS NULL OR deleted_at < $1)", deletedBefore)
+	if err != nil {
+		return counts, err
+	}
+	counts.Expired, err = r.purgeChunks(ctx, "expires_at < $1", expiredBefore)
+	return counts, err
+}
+
+// purgeChunks removes the URLs matching the condition chunk by chunk and returns how many were removed.
+// Rows locked by concurrent transactions are skipped until the next purge.
+func (r PgRepository) purgeChunks(ctx context.Context, condition string, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		WITH purged AS (
+			DELETE FROM url
+			WHERE id IN (
+				SELECT id FROM url
+				WHERE %s
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING short
+		), purged_clicks AS (
+			DELETE FROM click WHERE short IN (SELECT short FROM purged)
+		), purged_revisions AS (
+			DELETE FROM url_revision WHERE short IN (SELECT short FROM purged)
+		), purged_tags AS (
+			DELETE FROM url_tag WHERE short IN (SELECT short FROM purged)
+		)
+		SELECT COUNT(*) FROM purged
+	`, condition)
+	var total int64
+	for {
+		var count int64
+		err := r.pool.QueryRow(ctx, query, before, purgeChunkSize).Scan(&count)
+		if err != nil {
+			return total, err
+		}
+		total += count
+		if count < purgeChunkSize {
+			return total, nil
 		}
 	}
-	return urls, nil
 }
 
-// MarkDeletedUserURLs marks the specified URLs as deleted in PostgreSQL using a batch operation.
-func (r PgRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) {
+// PushDeletions stores the deletion requests in the deletion_queue table.
+func (r PgRepository) PushDeletions(ctx context.Context, urls ...URLForDelete) error {
+	shorts, userIDs := splitDeletions(urls)
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO deletion_queue (short, user_id)
+		SELECT * FROM unnest($1::text[], $2::bigint[])
+	`, shorts, userIDs)
+	return err
+}
+
+// PendingDeletions reads the deletion requests from the deletion_queue table.
+func (r PgRepository) PendingDeletions(ctx context.Context) ([]URLForDelete, error) {
+	rows, err := r.pool.Query(ctx, `SELECT short, user_id FROM deletion_queue ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (URLForDelete, error) {
+		var url URLForDelete
+		err := row.Scan(&url.ShortID, &url.UserID)
+		return url, err
+	})
+}
+
+// RemoveDeletions removes the deletion requests from the deletion_queue table.
+// All queued copies of a request are removed, which is fine since applying a deletion is idempotent.
+func (r PgRepository) RemoveDeletions(ctx context.Context, urls ...URLForDelete) error {
+	shorts, userIDs := splitDeletions(urls)
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM deletion_queue q
+		USING unnest($1::text[], $2::bigint[]) AS d(short, user_id)
+		WHERE q.short = d.short AND q.user_id = d.user_id
+	`, shorts, userIDs)
+	return err
+}
+
+// splitDeletions turns the deletion requests into parallel arrays for unnest.
+func splitDeletions(urls []URLForDelete) ([]string, []int64) {
+	shorts := make([]string, len(urls))
+	userIDs := make([]int64, len(urls))
+	for i, url := range urls {
+		shorts[i] = url.ShortID
+		userIDs[i] = url.UserID
+	}
+	return shorts, userIDs
+}
+
+// MarkDeletedUserURLs marks the specified URLs as deleted in PostgreSQL using a batch operation
+// and returns the outcome of each request.
+// URLs already in the trash keep their original deletion time.
+func (r PgRepository) MarkDeletedUserURLs(ctx context.Context, urls ...URLForDelete) ([]DeletionOutcome, error) {
 	batch := &pgx.Batch{}
 	for _, url := range urls {
-		batch.Queue("UPDATE url SET is_deleted=TRUE WHERE short=$1 AND user_id=$2", url.ShortID, url.UserID)
+		batch.Queue(`
+			WITH target AS (
+				SELECT user_id, is_deleted FROM url WHERE short = $1
+			), deleted AS (
+				UPDATE url SET is_deleted = TRUE, deleted_at = NOW()
+				WHERE short = $1 AND user_id = $2 AND NOT is_deleted
+				RETURNING 1
+			)
+			SELECT (SELECT user_id FROM target), (SELECT is_deleted FROM target), EXISTS(SELECT 1 FROM deleted)
+		`, url.ShortID, url.UserID)
 	}
 	results := r.pool.SendBatch(ctx, batch)
 	defer results.Close()
-	for i := range len(urls) {
-		_, err := results.Exec()
+	outcomes := make([]DeletionOutcome, len(urls))
+	for i, url := range urls {
+		var ownerID *int64
+		var isDeleted *bool
+		var deleted bool
+		if err := results.QueryRow().Scan(&ownerID, &isDeleted, &deleted); err != nil {
+			return nil, fmt.Errorf("error executing batch command %d: %w", i, err)
+		}
+		switch {
+		case deleted:
+			outcomes[i] = OutcomeDeleted
+		case ownerID == nil:
+			outcomes[i] = OutcomeNotFound
+		case *ownerID != url.UserID:
+			outcomes[i] = OutcomeNotOwned
+		default:
+			outcomes[i] = OutcomeAlreadyDeleted
+		}
+	}
+	return outcomes, nil
+}
+
+// MarkExpiredURLs flags the URLs whose expiry time is not after now and returns how many were flagged.
+func (r PgRepository) MarkExpiredURLs(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE url
+		SET is_expired = TRUE
+		WHERE expires_at <= $1 AND NOT is_expired
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// AddClicks stores click events in PostgreSQL using the COPY protocol.
+func (r PgRepository) AddClicks(ctx context.Context, events ...ClickEvent) error {
+	rows := make([][]any, len(events))
+	for i, e := range events {
+		rows[i] = []any{e.ShortID, e.ClickedAt, e.Referrer, e.UserAgent, e.Browser, e.IP}
+	}
+	_, err := r.pool.CopyFrom(ctx,
+		pgx.Identifier{"click"},
+		[]string{"short", "clicked_at", "referrer", "user_agent", "browser", "ip"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+// GetClickStats aggregates the clicks of a URL owned by the user in PostgreSQL.
+// It returns ErrURLNotFound if the URL does not exist, is deleted or belongs to another user.
+func (r PgRepository) GetClickStats(ctx context.Context, userID int64, short string) (ClickStats, error) {
+	var owned bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM url WHERE short = $1 AND user_id = $2 AND NOT is_deleted)
+	`, short, userID).Scan(&owned)
+	if err != nil {
+		return ClickStats{}, err
+	}
+	if !owned {
+		return ClickStats{}, ErrURLNotFound
+	}
+
+	stats := NewClickStats()
+	b := &pgx.Batch{}
+	b.Queue(`
+		SELECT to_char(clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD'), COUNT(*)
+		FROM click WHERE short = $1 GROUP BY 1
+	`, short)
+	b.Queue(`SELECT referrer, COUNT(*) FROM click WHERE short = $1 GROUP BY 1`, short)
+	b.Queue(`SELECT browser, COUNT(*) FROM click WHERE short = $1 GROUP BY 1`, short)
+	results := r.pool.SendBatch(ctx, b)
+	defer results.Close()
+	for _, breakdown := range []map[string]int64{stats.ByDay, stats.ByReferrer, stats.ByBrowser} {
+		rows, err := results.Query()
 		if err != nil {
-			fmt.Printf("error executing batch command %d: %v", i, err)
+			return ClickStats{}, err
 		}
+		var key string
+		var count int64
+		_, err = pgx.ForEachRow(rows, []any{&key, &count}, func() error {
+			breakdown[key] = count
+			return nil
+		})
+		if err != nil {
+			return ClickStats{}, err
+		}
+	}
+	for _, count := range stats.ByDay {
+		stats.Total += count
+	}
+	return stats, nil
+}
+
+// UpdateURL changes a URL owned by the user and records the change as a new revision in one transaction.
+// The URL row is locked for the duration of the change.
+func (r PgRepository) UpdateURL(ctx context.Context, userID int64, short string, apply func(*StoredURL) error) (StoredURL, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	url := StoredURL{ShortID: short}
+	err = tx.QueryRow(ctx, `
+		SELECT original, user_id, is_deleted, expires_at, is_expired, clicks_left, password_hash, redirect_type,
+			   query_policy, title, notes, created_at
+		FROM url
+		WHERE short = $1
+		FOR UPDATE
+	`, short).Scan(&url.OriginalURL, &url.UserID, &url.IsDeleted, &url.ExpiresAt, &url.IsExpired,
+		&url.ClicksLeft, &url.PasswordHash, &url.RedirectType, &url.QueryPolicy, &url.Title, &url.Notes, &url.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && (url.IsDeleted || url.UserID != userID) {
+		return StoredURL{}, ErrURLNotFound
+	}
+	if err != nil {
+		return StoredURL{}, err
+	}
+	updated := url
+	if err := apply(&updated); err != nil {
+		return StoredURL{}, err
+	}
+
+	now := time.Now()
+	if updated.OriginalURL != url.OriginalURL || url.Expired(now) && !updated.Expired(now) {
+		if err := markExpiredOriginals(ctx, tx, updated.OriginalURL); err != nil {
+			return StoredURL{}, err
+		}
+		var existingShort string
+		err = tx.QueryRow(ctx, `
+			SELECT short
+			FROM url
+			WHERE original = $1 AND short <> $2
+			  AND NOT is_expired
+			  AND CASE $4::text
+					  WHEN 'global' THEN TRUE
+					  WHEN 'user' THEN user_id = $3
+					  ELSE FALSE
+				  END
+			LIMIT 1
+		`, updated.OriginalURL, short, userID, string(r.dedup)).Scan(&existingShort)
+		if err == nil {
+			return StoredURL{}, NewOriginalExistError(existingShort)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return StoredURL{}, err
+		}
+	}
+
+	var last int64
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(revision), 0) FROM url_revision WHERE short = $1`, short).Scan(&last)
+	if err != nil {
+		return StoredURL{}, err
+	}
+	revisions := make([]Revision, 0, 2)
+	if last == 0 {
+		revisions = append(revisions, url.Revision(1, url.UserID, url.CreatedAt))
+		last = 1
+	}
+	revisions = append(revisions, updated.Revision(last+1, userID, time.Now()))
+	b := &pgx.Batch{}
+	for _, rev := range revisions {
+		b.Queue(`
+			INSERT INTO url_revision (short, revision, original, redirect_type, query_policy, expires_at, title, notes, author_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, rev.ShortID, rev.Number, rev.OriginalURL, rev.RedirectType, rev.QueryPolicy, rev.ExpiresAt, rev.Title, rev.Notes, rev.AuthorID,
+			rev.CreatedAt)
+	}
+	b.Queue(`
+		UPDATE url
+		SET original = $2, redirect_type = $3, expires_at = $4, is_expired = $5, query_policy = $6, title = $7, notes = $8
+		WHERE short = $1
+	`, short, updated.OriginalURL, updated.RedirectType, updated.ExpiresAt, updated.IsExpired, updated.QueryPolicy,
+		updated.Title, updated.Notes)
+	err = tx.SendBatch(ctx, b).Close()
+	if err != nil {
+		return StoredURL{}, err
+	}
+	return updated, tx.Commit(ctx)
+}
+
+// GetRevisions returns the revisions of a URL owned by the user from PostgreSQL.
+func (r PgRepository) GetRevisions(ctx context.Context, userID int64, short string) ([]Revision, error) {
+	url := StoredURL{ShortID: short}
+	err := r.pool.QueryRow(ctx, `
+		SELECT original, user_id, is_deleted, expires_at, redirect_type, query_policy, title, notes, created_at
+		FROM url
+		WHERE short = $1
+	`, short).Scan(&url.OriginalURL, &url.UserID, &url.IsDeleted, &url.ExpiresAt, &url.RedirectType, &url.QueryPolicy, &url.Title, &url.Notes,
+		&url.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && (url.IsDeleted || url.UserID != userID) {
+		return nil, ErrURLNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT revision, short, original, redirect_type, query_policy, expires_at, title, notes, author_id, created_at
+		FROM url_revision
+		WHERE short = $1
+		ORDER BY revision
+	`, short)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Revision, error) {
+		var rev Revision
+		err := row.Scan(&rev.Number, &rev.ShortID, &rev.OriginalURL, &rev.RedirectType, &rev.QueryPolicy, &rev.ExpiresAt, &rev.Title, &rev.Notes,
+			&rev.AuthorID, &rev.CreatedAt)
+		return rev, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return []Revision{url.Revision(1, url.UserID, url.CreatedAt)}, nil
 	}
+	return revisions, nil
 }
 
 // Close closes the PostgreSQL connection pool.
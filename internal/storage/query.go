@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// URLSort is the key the URLs of a user are ordered by; ties are broken by short ID.
+type URLSort string
+
+// URL sort keys.
+const (
+	SortByCreated URLSort = "created_at"
+	SortByShort   URLSort = "short_url"
+)
+
+// URLCursor is the position of a URL in the order of a query, the page continues after it.
+// CreatedAt is only used when sorting by creation time.
+type URLCursor struct {
+	CreatedAt time.Time
+	ShortID   string
+}
+
+// URLQuery selects and orders the URLs of a user; zero fields do not filter.
+type URLQuery struct {
+	// Tag keeps the URLs carrying the tag.
+	Tag string
+	// Search keeps the URLs whose original URL, title, notes or one of the tags contains the lowercase substring.
+	Search string
+	// Domain keeps the URLs whose original host is the domain or one of its subdomains.
+	Domain string
+	// CreatedAfter and CreatedBefore keep the URLs created in [CreatedAfter, CreatedBefore).
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Sort orders the URLs, by creation time if empty; Desc reverses the order.
+	Sort URLSort
+	Desc bool
+	// After keeps the URLs that come after the cursor in the order.
+	After *URLCursor
+	// Limit caps the number of URLs returned, zero means no limit.
+	Limit int
+}
+
+// match reports whether the URL passes the filters of the query; it ignores the cursor and the limit.
+func (q URLQuery) match(u StoredURL) bool {
+	switch {
+	case q.Tag != "" && !slices.Contains(u.Tags, q.Tag):
+		return false
+	case q.Search != "" && !matchSearch(u, q.Search):
+		return false
+	case q.Domain != "" && !inDomain(u.OriginalURL, q.Domain):
+		return false
+	case !q.CreatedAfter.IsZero() && u.CreatedAt.Before(q.CreatedAfter):
+		return false
+	case !q.CreatedBefore.IsZero() && !u.CreatedAt.Before(q.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+// compare orders two URLs by the sort key of the query.
+func (q URLQuery) compare(a, b URLCursor) int {
+	c := 0
+	if q.Sort != SortByShort {
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ShortID, b.ShortID)
+	}
+	if q.Desc {
+		return -c
+	}
+	return c
+}
+
+// page orders the matching URLs and cuts the page out of them.
+func (q URLQuery) page(urls []StoredURL) []StoredURL {
+	slices.SortFunc(urls, func(a, b StoredURL) int {
+		return q.compare(a.Cursor(), b.Cursor())
+	})
+	if q.After != nil {
+		i, _ := slices.BinarySearchFunc(urls, *q.After, func(u StoredURL, after URLCursor) int {
+			if q.compare(u.Cursor(), after) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		urls = urls[i:]
+	}
+	if q.Limit > 0 && len(urls) > q.Limit {
+		urls = urls[:q.Limit]
+	}
+	return urls
+}
+
+// Cursor returns the position of the URL in a query order.
+func (u StoredURL) Cursor() URLCursor {
+	return URLCursor{CreatedAt: u.CreatedAt, ShortID: u.ShortID}
+}
+
+// inDomain reports whether the host of the original URL is the domain or one of its subdomains.
+func inDomain(original, domain string) bool {
+	parsed, err := url.Parse(original)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"strings"
+)
+
+// searchIndex is a trigram index over the original URLs, titles, notes and tags of the in-memory URLs.
+// It narrows a substring search down to the URLs containing every trigram of the query;
+// the candidates still have to be checked with URLQuery.match.
+type searchIndex struct {
+	trigrams map[string]map[string]struct{}
+	texts    map[string]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		trigrams: make(map[string]map[string]struct{}),
+		texts:    make(map[string]string),
+	}
+}
+
+// searchText returns the text of the URL a search looks into. Fields are separated by
+// a zero byte, so that no trigram of a query spans two of them.
+func searchText(u StoredURL) string {
+	fields := append([]string{u.OriginalURL, u.Title, u.Notes}, u.Tags...)
+	return strings.ToLower(strings.Join(fields, "\x00"))
+}
+
+// matchSearch reports whether the original URL, title, notes or one of the tags of the URL
+// contains the lowercase query.
+func matchSearch(u StoredURL, query string) bool {
+	for _, field := range []string{u.OriginalURL, u.Title, u.Notes} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	for _, tag := range u.Tags {
+		if strings.Contains(tag, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// trigrams returns the unique three-rune substrings of the text.
+func trigrams(text string) []string {
+	runes := []rune(text)
+	seen := make(map[string]struct{}, len(runes))
+	grams := make([]string, 0, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		gram := string(runes[i : i+3])
+		if _, ok := seen[gram]; !ok {
+			seen[gram] = struct{}{}
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// put indexes the URL, replacing its previous text.
+func (idx *searchIndex) put(u StoredURL) {
+	text := searchText(u)
+	if old, ok := idx.texts[u.ShortID]; ok {
+		if old == text {
+			return
+		}
+		idx.remove(u.ShortID)
+	}
+	idx.texts[u.ShortID] = text
+	for _, gram := range trigrams(text) {
+		shorts, ok := idx.trigrams[gram]
+		if !ok {
+			shorts = make(map[string]struct{})
+			idx.trigrams[gram] = shorts
+		}
+		shorts[u.ShortID] = struct{}{}
+	}
+}
+
+// remove drops the URL from the index.
+func (idx *searchIndex) remove(short string) {
+	text, ok := idx.texts[short]
+	if !ok {
+		return
+	}
+	delete(idx.texts, short)
+	for _, gram := range trigrams(text) {
+		delete(idx.trigrams[gram], short)
+		if len(idx.trigrams[gram]) == 0 {
+			delete(idx.trigrams, gram)
+		}
+	}
+}
+
+// find returns the short IDs of the URLs that may contain the lowercase query.
+// It returns false if the query is too short to use the index.
+func (idx *searchIndex) find(query string) ([]string, bool) {
+	grams := trigrams(query)
+	if len(grams) == 0 {
+		return nil, false
+	}
+	smallest := idx.trigrams[grams[0]]
+	for _, gram := range grams[1:] {
+		if shorts := idx.trigrams[gram]; len(shorts) < len(smallest) {
+			smallest = shorts
+		}
+	}
+	found := make([]string, 0, len(smallest))
+	for short := range smallest {
+		if idx.has(short, grams) {
+			found = append(found, short)
+		}
+	}
+	return found, true
+}
+
+// has reports whether the URL text contains all the trigrams.
+func (idx *searchIndex) has(short string, grams []string) bool {
+	for _, gram := range grams {
+		if _, ok := idx.trigrams[gram][short]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -7,6 +7,7 @@ package storage_mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	storage "github.com/cmrd-a/shortener/internal/storage"
 	gomock "github.com/golang/mock/gomock"
@@ -36,17 +37,17 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockRepository) Add(arg0 context.Context, arg1, arg2 string, arg3 int64) error {
+func (m *MockRepository) Add(arg0 context.Context, arg1 storage.StoredURL) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "Add", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockRepositoryMockRecorder) Add(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Add(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockRepository)(nil).Add), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockRepository)(nil).Add), arg0, arg1)
 }
 
 // AddBatch mocks base method.
@@ -68,11 +69,79 @@ func (mr *MockRepositoryMockRecorder) AddBatch(arg0, arg1 interface{}, arg2 ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockRepository)(nil).AddBatch), varargs...)
 }
 
+// AddClicks mocks base method.
+func (m *MockRepository) AddClicks(arg0 context.Context, arg1 ...storage.ClickEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddClicks", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddClicks indicates an expected call of AddClicks.
+func (mr *MockRepositoryMockRecorder) AddClicks(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClicks", reflect.TypeOf((*MockRepository)(nil).AddClicks), varargs...)
+}
+
+// ConsumeClick mocks base method.
+func (m *MockRepository) ConsumeClick(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockRepositoryMockRecorder) ConsumeClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockRepository)(nil).ConsumeClick), arg0, arg1)
+}
+
+// ExportUserURLs mocks base method.
+func (m *MockRepository) ExportUserURLs(arg0 context.Context, arg1 int64) storage.URLExportSeq {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserURLs", arg0, arg1)
+	ret0, _ := ret[0].(storage.URLExportSeq)
+	return ret0
+}
+
+// ExportUserURLs indicates an expected call of ExportUserURLs.
+func (mr *MockRepositoryMockRecorder) ExportUserURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserURLs", reflect.TypeOf((*MockRepository)(nil).ExportUserURLs), arg0, arg1)
+}
+
+// FindOriginals mocks base method.
+func (m *MockRepository) FindOriginals(arg0 context.Context, arg1 int64, arg2 ...string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindOriginals", varargs...)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOriginals indicates an expected call of FindOriginals.
+func (mr *MockRepositoryMockRecorder) FindOriginals(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOriginals", reflect.TypeOf((*MockRepository)(nil).FindOriginals), varargs...)
+}
+
 // Get mocks base method.
-func (m *MockRepository) Get(arg0 context.Context, arg1 string) (string, error) {
+func (m *MockRepository) Get(arg0 context.Context, arg1 string) (storage.StoredURL, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(storage.StoredURL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -83,29 +152,92 @@ func (mr *MockRepositoryMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), arg0, arg1)
 }
 
+// GetClickStats mocks base method.
+func (m *MockRepository) GetClickStats(arg0 context.Context, arg1 int64, arg2 string) (storage.ClickStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClickStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(storage.ClickStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClickStats indicates an expected call of GetClickStats.
+func (mr *MockRepositoryMockRecorder) GetClickStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickStats", reflect.TypeOf((*MockRepository)(nil).GetClickStats), arg0, arg1, arg2)
+}
+
+// GetDeletedUserURLs mocks base method.
+func (m *MockRepository) GetDeletedUserURLs(arg0 context.Context, arg1 int64) ([]storage.StoredURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedUserURLs", arg0, arg1)
+	ret0, _ := ret[0].([]storage.StoredURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedUserURLs indicates an expected call of GetDeletedUserURLs.
+func (mr *MockRepositoryMockRecorder) GetDeletedUserURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedUserURLs", reflect.TypeOf((*MockRepository)(nil).GetDeletedUserURLs), arg0, arg1)
+}
+
+// GetRevisions mocks base method.
+func (m *MockRepository) GetRevisions(arg0 context.Context, arg1 int64, arg2 string) ([]storage.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]storage.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockRepositoryMockRecorder) GetRevisions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockRepository)(nil).GetRevisions), arg0, arg1, arg2)
+}
+
+// GetUserTags mocks base method.
+func (m *MockRepository) GetUserTags(arg0 context.Context, arg1 int64) ([]storage.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTags", arg0, arg1)
+	ret0, _ := ret[0].([]storage.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTags indicates an expected call of GetUserTags.
+func (mr *MockRepositoryMockRecorder) GetUserTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTags", reflect.TypeOf((*MockRepository)(nil).GetUserTags), arg0, arg1)
+}
+
 // GetUserURLs mocks base method.
-func (m *MockRepository) GetUserURLs(arg0 context.Context, arg1 int64) ([]storage.StoredURL, error) {
+func (m *MockRepository) GetUserURLs(arg0 context.Context, arg1 int64, arg2 storage.URLQuery) ([]storage.StoredURL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserURLs", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetUserURLs", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]storage.StoredURL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserURLs indicates an expected call of GetUserURLs.
-func (mr *MockRepositoryMockRecorder) GetUserURLs(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetUserURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockRepository)(nil).GetUserURLs), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockRepository)(nil).GetUserURLs), arg0, arg1, arg2)
 }
 
 // MarkDeletedUserURLs mocks base method.
-func (m *MockRepository) MarkDeletedUserURLs(arg0 context.Context, arg1 ...storage.URLForDelete) {
+func (m *MockRepository) MarkDeletedUserURLs(arg0 context.Context, arg1 ...storage.URLForDelete) ([]storage.DeletionOutcome, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0}
 	for _, a := range arg1 {
 		varargs = append(varargs, a)
 	}
-	m.ctrl.Call(m, "MarkDeletedUserURLs", varargs...)
+	ret := m.ctrl.Call(m, "MarkDeletedUserURLs", varargs...)
+	ret0, _ := ret[0].([]storage.DeletionOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // MarkDeletedUserURLs indicates an expected call of MarkDeletedUserURLs.
@@ -115,6 +247,21 @@ func (mr *MockRepositoryMockRecorder) MarkDeletedUserURLs(arg0 interface{}, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeletedUserURLs", reflect.TypeOf((*MockRepository)(nil).MarkDeletedUserURLs), varargs...)
 }
 
+// MarkExpiredURLs mocks base method.
+func (m *MockRepository) MarkExpiredURLs(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExpiredURLs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkExpiredURLs indicates an expected call of MarkExpiredURLs.
+func (mr *MockRepositoryMockRecorder) MarkExpiredURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpiredURLs", reflect.TypeOf((*MockRepository)(nil).MarkExpiredURLs), arg0, arg1)
+}
+
 // Ping mocks base method.
 func (m *MockRepository) Ping(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -128,3 +275,68 @@ func (mr *MockRepositoryMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), arg0)
 }
+
+// PurgeURLs mocks base method.
+func (m *MockRepository) PurgeURLs(arg0 context.Context, arg1, arg2 time.Time) (storage.PurgeCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeURLs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(storage.PurgeCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeURLs indicates an expected call of PurgeURLs.
+func (mr *MockRepositoryMockRecorder) PurgeURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeURLs", reflect.TypeOf((*MockRepository)(nil).PurgeURLs), arg0, arg1, arg2)
+}
+
+// RestoreUserURLs mocks base method.
+func (m *MockRepository) RestoreUserURLs(arg0 context.Context, arg1 int64, arg2 time.Time, arg3 ...string) ([]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreUserURLs", varargs...)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUserURLs indicates an expected call of RestoreUserURLs.
+func (mr *MockRepositoryMockRecorder) RestoreUserURLs(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserURLs", reflect.TypeOf((*MockRepository)(nil).RestoreUserURLs), varargs...)
+}
+
+// SetTags mocks base method.
+func (m *MockRepository) SetTags(arg0 context.Context, arg1 int64, arg2 string, arg3 []string) (storage.StoredURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTags", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(storage.StoredURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTags indicates an expected call of SetTags.
+func (mr *MockRepositoryMockRecorder) SetTags(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTags", reflect.TypeOf((*MockRepository)(nil).SetTags), arg0, arg1, arg2, arg3)
+}
+
+// UpdateURL mocks base method.
+func (m *MockRepository) UpdateURL(arg0 context.Context, arg1 int64, arg2 string, arg3 func(*storage.StoredURL) error) (storage.StoredURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(storage.StoredURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockRepositoryMockRecorder) UpdateURL(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockRepository)(nil).UpdateURL), arg0, arg1, arg2, arg3)
+}